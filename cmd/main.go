@@ -1,5 +1,53 @@
 package main
 
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/app"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/config"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/logger"
+)
+
+const configPathEnv = "CONFIG_PATH"
+
 func main() {
+	configPath := flag.String("config", "config/config.yaml", "path to the config file")
+	flag.Parse()
+
+	if path, ok := os.LookupEnv(configPathEnv); ok {
+		*configPath = path
+	}
+
+	if err := run(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string) error {
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	log, logCloser, err := logger.NewLogger(cfg.Logger.Level, cfg.Logger.Path)
+	if err != nil {
+		return fmt.Errorf("init logger: %w", err)
+	}
+	defer logCloser.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	application, err := app.NewApp(ctx, cfg, log)
+	if err != nil {
+		return fmt.Errorf("init app: %w", err)
+	}
 
+	return application.Run(ctx)
 }
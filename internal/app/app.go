@@ -0,0 +1,425 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/workers"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/cache"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/clients"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/postgres"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/events"
+	pgrepo "github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/repository"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/config"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/metrics"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/password"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/refcode"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/secrets"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/server"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/tokens"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/tracing"
+	http_handlers "github.com/rozhnof/stakewolle-auth-service/internal/presentation/handlers"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/ginauth"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/verifier"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// worker is a background job that runs until its context is cancelled.
+type worker interface {
+	Run(ctx context.Context)
+}
+
+type App struct {
+	cfg            *config.Config
+	log            *slog.Logger
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	postgres       postgres.Database
+	redis          redis.Database
+	server         *server.HTTPServer
+	metricsServer  *server.HTTPServer
+	workers        []worker
+}
+
+func NewApp(ctx context.Context, cfg *config.Config, log *slog.Logger) (_ *App, err error) {
+	app := &App{
+		cfg: cfg,
+		log: log,
+	}
+
+	// release whatever has already been acquired if a later step fails
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, app.close(context.WithoutCancel(ctx)))
+		}
+	}()
+
+	app.tracerProvider, err = tracing.NewTracerProvider(ctx, tracing.TracerProviderConfig{
+		ServiceName: cfg.Tracing.ServiceName,
+		Endpoint:    cfg.Tracing.Endpoint,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init tracer provider: %w", err)
+	}
+
+	tracer := app.tracerProvider.Tracer(cfg.Tracing.ServiceName)
+
+	var metricsHandler http.Handler
+
+	app.meterProvider, metricsHandler, err = metrics.NewMeterProvider()
+	if err != nil {
+		return nil, fmt.Errorf("init meter provider: %w", err)
+	}
+
+	meter := app.meterProvider.Meter(cfg.Tracing.ServiceName)
+
+	app.postgres, err = postgres.NewDatabase(ctx, postgres.DatabaseConfig{
+		Address:  cfg.Postgres.Address,
+		Port:     cfg.Postgres.Port,
+		User:     cfg.Postgres.User,
+		Password: cfg.Postgres.Password,
+		DB:       cfg.Postgres.DB,
+		SSL:      cfg.Postgres.SSL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init postgres: %w", err)
+	}
+
+	app.redis, err = redis.NewDatabase(ctx, redis.DatabaseConfig{
+		Address:      cfg.Redis.Address,
+		Port:         cfg.Redis.Port,
+		User:         cfg.Redis.User,
+		Password:     cfg.Redis.Password,
+		UserPassword: cfg.Redis.UserPassword,
+		DB:           cfg.Redis.DB,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init redis: %w", err)
+	}
+
+	txManager := postgres.NewTransactionManager(app.postgres.Pool)
+
+	invalidationBus := cache.NewInvalidationBus(app.redis, log)
+	app.workers = append(app.workers, invalidationBus)
+
+	var userCache repository.UserCache = cache.NewUserCache(app.redis)
+	if cfg.Cache.UserLocalSize > 0 {
+		userCache = cache.NewLocalUserCache(cache.LocalCacheConfig{
+			Size: cfg.Cache.UserLocalSize,
+			TTL:  cfg.Cache.UserLocalTTL,
+		}, userCache, invalidationBus)
+	}
+
+	userRepository, err := cache.NewCachedUserRepository(
+		pgrepo.NewUserRepository(app.postgres, *txManager, log, tracer),
+		userCache,
+		cache.Config{
+			TTL:         cfg.Cache.UserTTL,
+			NegativeTTL: cfg.Cache.UserNegativeTTL,
+			Beta:        cfg.Cache.EarlyRefresh,
+		},
+		txManager,
+		invalidationBus,
+		meter,
+		log,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("init user repository: %w", err)
+	}
+
+	sessionRepository, err := cache.NewCachedSessionRepository(
+		pgrepo.NewSessionRepository(app.postgres, *txManager, log, tracer),
+		cache.NewSessionCache(app.redis),
+		cache.Config{
+			TTL:  cfg.Cache.SessionTTL,
+			Beta: cfg.Cache.EarlyRefresh,
+		},
+		txManager,
+		meter,
+		log,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("init session repository: %w", err)
+	}
+
+	referralCodeRepository := pgrepo.NewReferralCodeRepository(app.postgres, *txManager, log, tracer)
+	outboxRepository := pgrepo.NewOutboxRepository(app.postgres, *txManager, log, tracer)
+
+	eventPublisher := events.NewRedisStreamPublisher(app.redis, events.RedisStreamConfig{
+		Stream: cfg.Events.Stream,
+		MaxLen: cfg.Events.StreamMaxLen,
+	})
+
+	outboxRelay, err := workers.NewOutboxRelay(workers.OutboxRelayConfig{
+		Interval:  cfg.Events.RelayInterval,
+		BatchSize: cfg.Events.RelayBatchSize,
+	}, outboxRepository, txManager, eventPublisher, meter, log, tracer)
+	if err != nil {
+		return nil, fmt.Errorf("init outbox relay: %w", err)
+	}
+
+	app.workers = append(app.workers, outboxRelay)
+
+	if cfg.Sessions.CleanupInterval > 0 {
+		sessionJanitor, err := workers.NewSessionJanitor(workers.SessionJanitorConfig{
+			Interval:  cfg.Sessions.CleanupInterval,
+			Retention: cfg.Sessions.CleanupRetention,
+			BatchSize: cfg.Sessions.CleanupBatchSize,
+		}, sessionRepository, txManager, postgres.NewAdvisoryLocker(txManager), meter, log, tracer)
+		if err != nil {
+			return nil, fmt.Errorf("init session janitor: %w", err)
+		}
+
+		app.workers = append(app.workers, sessionJanitor)
+	}
+
+	tokenDenylist := cache.NewTokenDenylist(app.redis)
+	sessionDenylist := cache.NewSessionDenylist(app.redis)
+
+	signingKeyRepository := pgrepo.NewSigningKeyRepository(app.postgres, *txManager, log, tracer)
+
+	keyRing, err := tokens.LoadKeyRing(ctx, tokens.KeyRingConfig{
+		Algorithm:       cfg.Tokens.SigningAlgorithm,
+		KeyPath:         cfg.Tokens.SigningKeyPath,
+		RetiredKeyPaths: cfg.Tokens.RetiredKeyPaths,
+		Retention:       cfg.Tokens.AccessTokenTTL,
+		Rotating:        cfg.Tokens.RotationInterval > 0,
+	}, secrets.SecretManager{}, signingKeyRepository)
+	if err != nil {
+		return nil, fmt.Errorf("load signing keys: %w", err)
+	}
+
+	if cfg.Tokens.RotationInterval > 0 {
+		keyRotator, err := tokens.NewKeyRotator(tokens.KeyRotatorConfig{
+			Algorithm:    cfg.Tokens.SigningAlgorithm,
+			Interval:     cfg.Tokens.RotationInterval,
+			PublishLead:  cfg.Tokens.KeyPublishLead,
+			SyncInterval: cfg.Tokens.KeySyncInterval,
+		}, keyRing, signingKeyRepository, txManager, postgres.NewAdvisoryLocker(txManager), log)
+		if err != nil {
+			return nil, fmt.Errorf("init key rotator: %w", err)
+		}
+
+		app.workers = append(app.workers, keyRotator)
+	}
+
+	tokenManager := tokens.NewTokenManager(tokens.TokenManagerConfig{
+		AccessTokenTTL:  cfg.Tokens.AccessTokenTTL,
+		RefreshTokenTTL: cfg.Tokens.RefreshTokenTTL,
+		Issuer:          cfg.Tokens.Issuer,
+		Audience:        cfg.Tokens.Audience,
+	}, keyRing)
+
+	passwordManager := password.NewPasswordManager()
+
+	sessionPolicy, err := models.NewSessionPolicy(cfg.Sessions.Policy, cfg.Sessions.MaxSessions)
+	if err != nil {
+		return nil, fmt.Errorf("parse session policy: %w", err)
+	}
+
+	userService, err := services.NewUserService(services.Dependencies{
+		UserRepository:         userRepository,
+		SessionRepository:      sessionRepository,
+		ReferralCodeRepository: referralCodeRepository,
+		OutboxRepository:       outboxRepository,
+		TokenDenylist:          tokenDenylist,
+		SessionDenylist:        sessionDenylist,
+		TransactionManager:     txManager,
+		TokenManager:           tokenManager,
+		PasswordManager:        passwordManager,
+		SecurityEvents:         events.NewSecurityEventLogger(log),
+	}, services.UserServiceConfig{
+		SessionPolicy: sessionPolicy,
+		SessionLimits: models.SessionLimits{
+			IdleTimeout: cfg.Tokens.IdleTimeout,
+			MaxLifetime: cfg.Tokens.MaxLifetime,
+		},
+		AccessTokenTTL: cfg.Tokens.AccessTokenTTL,
+	}, log, tracer)
+	if err != nil {
+		return nil, fmt.Errorf("init user service: %w", err)
+	}
+
+	profanityFilter := refcode.NewFilter(cfg.Referral.BlockedWords...)
+
+	lookupLimiter, err := cache.NewRateLimiter(app.redis, cfg.Referral.LookupLimit, cfg.Referral.LookupWindow)
+	if err != nil {
+		return nil, fmt.Errorf("init referral lookup limiter: %w", err)
+	}
+
+	codeGenerator, err := refcode.NewGenerator(cfg.Referral.CodeLength, profanityFilter)
+	if err != nil {
+		return nil, fmt.Errorf("init referral code generator: %w", err)
+	}
+
+	referralService, err := services.NewReferralService(services.ReferralDependencies{
+		ReferralCodeRepository: referralCodeRepository,
+		ReferralRepository:     pgrepo.NewReferralRepository(app.postgres, *txManager, log, tracer),
+		UserRepository:         userRepository,
+		TransactionManager:     txManager,
+		LookupLimiter:          lookupLimiter,
+		CodeGenerator:          codeGenerator,
+		ProfanityFilter:        profanityFilter,
+	}, services.ReferralServiceConfig{
+		CodeTTL:      cfg.Referral.CodeTTL,
+		MaxCodeTTL:   cfg.Referral.MaxCodeTTL,
+		TreeMaxDepth: cfg.Referral.TreeMaxDepth,
+		TreeMaxNodes: cfg.Referral.TreeMaxNodes,
+	}, log, tracer)
+	if err != nil {
+		return nil, fmt.Errorf("init referral service: %w", err)
+	}
+
+	tokenVerifier := verifier.New(tokens.NewVerifierKeySet(keyRing), verifier.Config{
+		Issuer:          cfg.Tokens.Issuer,
+		Audiences:       cfg.Tokens.Audience,
+		Leeway:          cfg.Tokens.ClockSkew,
+		Algorithms:      []string{cfg.Tokens.SigningAlgorithm},
+		Denylist:        tokenDenylist,
+		SessionDenylist: sessionDenylist,
+	})
+
+	oauthClients := make([]models.Client, 0, len(cfg.OAuth.Clients))
+	for _, client := range cfg.OAuth.Clients {
+		oauthClients = append(oauthClients, models.Client{
+			ID:         client.ID,
+			SecretHash: client.SecretHash,
+		})
+	}
+
+	oauthService, err := services.NewOAuthService(services.OAuthDependencies{
+		ClientRepository:  clients.NewStaticClientRepository(oauthClients),
+		SessionRepository: sessionRepository,
+		UserRepository:    userRepository,
+		TokenDenylist:     tokenDenylist,
+		SessionDenylist:   sessionDenylist,
+		TokenVerifier:     tokenVerifier,
+		PasswordManager:   passwordManager,
+	}, services.OAuthServiceConfig{
+		AccessTokenTTL: cfg.Tokens.AccessTokenTTL,
+	}, log, tracer)
+	if err != nil {
+		return nil, fmt.Errorf("init oauth service: %w", err)
+	}
+
+	router, err := newRouter(cfg, handlers{
+		authenticate: ginauth.Authenticate(tokenVerifier),
+		auth:         http_handlers.NewAuthHandler(userService, log, tracer),
+		oauth:        http_handlers.NewOAuthHandler(oauthService, log, tracer),
+		wellKnown:    http_handlers.NewWellKnownHandler(tokenManager, log, tracer),
+		referral:     http_handlers.NewReferralHandler(referralService, log, tracer),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init router: %w", err)
+	}
+
+	app.server = server.NewHTTPServer(context.WithoutCancel(ctx), cfg.Server.Address, router)
+	app.metricsServer = server.NewHTTPServer(context.WithoutCancel(ctx), cfg.Server.MetricsAddress, newMetricsRouter(metricsHandler))
+
+	return app, nil
+}
+
+// Run serves HTTP and runs the background workers until ctx is cancelled or
+// the server fails, then shuts everything down within the configured
+// shutdown timeout.
+func (a *App) Run(ctx context.Context) error {
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for _, w := range a.workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			w.Run(workersCtx)
+		}()
+	}
+
+	serverErr := make(chan error, 2)
+
+	go func() {
+		a.log.Info("http server started", slog.String("address", a.cfg.Server.Address))
+
+		if err := a.server.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- fmt.Errorf("http server: %w", err)
+		}
+	}()
+
+	go func() {
+		a.log.Info("metrics server started", slog.String("address", a.cfg.Server.MetricsAddress))
+
+		if err := a.metricsServer.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- fmt.Errorf("metrics server: %w", err)
+		}
+	}()
+
+	var runErr error
+
+	select {
+	case <-ctx.Done():
+		a.log.Info("shutdown signal received")
+	case runErr = <-serverErr:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := a.server.Shutdown(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, fmt.Errorf("shutdown http server: %w", err))
+	}
+
+	if err := a.metricsServer.Shutdown(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, fmt.Errorf("shutdown metrics server: %w", err))
+	}
+
+	stopWorkers()
+	wg.Wait()
+
+	if err := a.close(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, err)
+	}
+
+	a.log.Info("shutdown completed")
+
+	return runErr
+}
+
+// close releases the storage clients and flushes pending spans, in reverse
+// order of initialization. It is safe to call on a partially built App.
+func (a *App) close(ctx context.Context) error {
+	var err error
+
+	if a.redis.Client != nil {
+		if closeErr := a.redis.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close redis: %w", closeErr))
+		}
+	}
+
+	if a.postgres.Pool != nil {
+		a.postgres.Close()
+	}
+
+	if a.meterProvider != nil {
+		if shutdownErr := a.meterProvider.Shutdown(ctx); shutdownErr != nil {
+			err = errors.Join(err, fmt.Errorf("shutdown meter provider: %w", shutdownErr))
+		}
+	}
+
+	if a.tracerProvider != nil {
+		if shutdownErr := a.tracerProvider.Shutdown(ctx); shutdownErr != nil {
+			err = errors.Join(err, fmt.Errorf("shutdown tracer provider: %w", shutdownErr))
+		}
+	}
+
+	return err
+}
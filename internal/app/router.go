@@ -0,0 +1,85 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/config"
+	http_handlers "github.com/rozhnof/stakewolle-auth-service/internal/presentation/handlers"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/ginauth"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+type handlers struct {
+	authenticate gin.HandlerFunc
+	auth         *http_handlers.AuthHandler
+	oauth        *http_handlers.OAuthHandler
+	wellKnown    *http_handlers.WellKnownHandler
+	referral     *http_handlers.ReferralHandler
+}
+
+func newRouter(cfg *config.Config, h handlers) (*gin.Engine, error) {
+	gin.SetMode(cfg.Mode)
+
+	router := gin.New()
+
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	router.Use(gin.Recovery())
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+
+	auth := router.Group("/auth")
+	{
+		auth.POST("/register", h.auth.Register)
+		auth.POST("/login", h.auth.Login)
+		auth.POST("/refresh", h.auth.Refresh)
+
+		authenticated := auth.Group("", h.authenticate)
+		authenticated.GET("/sessions", h.auth.ListSessions)
+		authenticated.DELETE("/sessions/:id", h.auth.RevokeSession)
+		authenticated.POST("/logout", h.auth.Logout)
+		authenticated.POST("/logout-all", h.auth.LogoutAll)
+	}
+
+	referral := router.Group("/referral-code")
+	{
+		referral.GET("/lookup", h.referral.LookupReferralCode)
+
+		authenticated := referral.Group("", h.authenticate)
+		authenticated.POST("", h.referral.CreateReferralCode)
+		authenticated.GET("", h.referral.GetReferralCode)
+		authenticated.DELETE("", h.referral.DeleteReferralCode)
+		authenticated.PUT("/discoverable", h.referral.SetReferralDiscoverable)
+	}
+
+	users := router.Group("/users/:id", h.authenticate, ginauth.RequireSelfOrRole("id", models.RoleAdmin))
+	{
+		users.GET("/referrals", h.referral.ListReferrals)
+		users.GET("/referrals/stats", h.referral.ReferralStats)
+		users.GET("/referrals/tree", h.referral.ReferralTree)
+	}
+
+	router.GET("/referral-campaigns", h.authenticate, ginauth.RequireRole(models.RoleAdmin), h.referral.ListCampaigns)
+
+	oauth := router.Group("/oauth", h.oauth.AuthenticateClient)
+	{
+		oauth.POST("/introspect", h.oauth.Introspect)
+		oauth.POST("/revoke", h.oauth.Revoke)
+	}
+
+	router.GET("/.well-known/jwks.json", h.wellKnown.JWKS)
+
+	return router, nil
+}
+
+// newMetricsRouter serves the metrics on their own listener, so they are never
+// exposed on the public address.
+func newMetricsRouter(metrics http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+
+	return mux
+}
@@ -4,5 +4,20 @@ import "errors"
 
 var (
 	ErrUnauthorizedRefresh = errors.New("unauthorized refresh")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
 	ErrInvalidPassword     = errors.New("invalid password")
+	ErrInvalidClient       = errors.New("invalid client")
+	ErrSessionNotFound     = errors.New("session not found")
+
+	ErrReferralCodeExists        = errors.New("referral code exists")
+	ErrReferralCodeNotFound      = errors.New("referral code not found")
+	ErrInvalidReferralCode       = errors.New("invalid referral code")
+	ErrInvalidReferralCodeExpiry = errors.New("invalid referral code expiry")
+	ErrReferralCodeTaken         = errors.New("referral code taken")
+	ErrReferralCodeExhausted     = errors.New("referral code exhausted")
+	ErrInvalidVanityCode         = errors.New("invalid vanity referral code")
+	ErrProfaneReferralCode       = errors.New("profane referral code")
+	ErrInvalidCampaign           = errors.New("invalid campaign")
+	ErrInvalidMaxRedemptions     = errors.New("invalid max redemptions")
+	ErrRateLimited               = errors.New("rate limited")
 )
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/verifier"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TokenTypeAccessToken  = "access_token"
+	TokenTypeRefreshToken = "refresh_token"
+)
+
+// denylistGracePeriod keeps revoked token IDs a little past their expiry, so
+// verifiers that tolerate clock skew still see them as revoked.
+const denylistGracePeriod = time.Minute
+
+type AccessTokenVerifier interface {
+	Verify(ctx context.Context, token string) (*verifier.Principal, error)
+}
+
+type OAuthDependencies struct {
+	ClientRepository  repository.ClientRepository
+	SessionRepository repository.SessionRepository
+	UserRepository    repository.UserRepository
+	TokenDenylist     repository.TokenDenylist
+	SessionDenylist   repository.SessionDenylist
+	TokenVerifier     AccessTokenVerifier
+	PasswordManager   PasswordManager
+}
+
+func (d OAuthDependencies) Valid() error {
+	if d.ClientRepository == nil {
+		return errors.New("missing client repository")
+	}
+
+	if d.SessionRepository == nil {
+		return errors.New("missing session repository")
+	}
+
+	if d.UserRepository == nil {
+		return errors.New("missing user repository")
+	}
+
+	if d.TokenDenylist == nil {
+		return errors.New("missing token denylist")
+	}
+
+	if d.SessionDenylist == nil {
+		return errors.New("missing session denylist")
+	}
+
+	if d.TokenVerifier == nil {
+		return errors.New("missing token verifier")
+	}
+
+	if d.PasswordManager == nil {
+		return errors.New("missing password manager")
+	}
+
+	return nil
+}
+
+// TokenIntrospection is the state of a token as described in RFC 7662.
+// Only Active is meaningful when the token is not active.
+type TokenIntrospection struct {
+	Active    bool
+	TokenType string
+	TokenID   string
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	Username  string
+	Scope     string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+}
+
+type OAuthServiceConfig struct {
+	// AccessTokenTTL bounds how long a revoked session stays denylisted.
+	AccessTokenTTL time.Duration
+}
+
+type OAuthService struct {
+	OAuthDependencies
+	cfg    OAuthServiceConfig
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewOAuthService(d OAuthDependencies, cfg OAuthServiceConfig, log *slog.Logger, tracer trace.Tracer) (*OAuthService, error) {
+	if err := d.Valid(); err != nil {
+		return nil, errors.Wrap(err, "missing required dependency")
+	}
+
+	return &OAuthService{
+		OAuthDependencies: d,
+		cfg:               cfg,
+		log:               log,
+		tracer:            tracer,
+	}, nil
+}
+
+func (s *OAuthService) AuthenticateClient(ctx context.Context, clientID string, clientSecret string) error {
+	ctx, span := s.tracer.Start(ctx, "OAuthService.AuthenticateClient")
+	defer span.End()
+
+	client, err := s.ClientRepository.GetByID(ctx, clientID)
+	if err != nil {
+		return ErrInvalidClient
+	}
+
+	if !s.PasswordManager.CheckPassword(clientSecret, client.SecretHash) {
+		return ErrInvalidClient
+	}
+
+	return nil
+}
+
+// Introspect reports whether the token is live. The hint only decides which
+// token type is tried first, as RFC 7662 requires a fallback to the others.
+// An error means liveness could not be checked, it is not reported as an
+// inactive token.
+func (s *OAuthService) Introspect(ctx context.Context, token string, tokenTypeHint string) (*TokenIntrospection, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthService.Introspect")
+	defer span.End()
+
+	introspectors := []func(context.Context, string) (*TokenIntrospection, error){
+		s.introspectAccessToken,
+		s.introspectRefreshToken,
+	}
+
+	if tokenTypeHint == TokenTypeRefreshToken {
+		introspectors[0], introspectors[1] = introspectors[1], introspectors[0]
+	}
+
+	for _, introspect := range introspectors {
+		introspection, err := introspect(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if introspection.Active {
+			return introspection, nil
+		}
+	}
+
+	return &TokenIntrospection{Active: false}, nil
+}
+
+func (s *OAuthService) introspectAccessToken(ctx context.Context, token string) (*TokenIntrospection, error) {
+	principal, err := s.TokenVerifier.Verify(ctx, token)
+	if err != nil {
+		if verifier.IsInvalidToken(err) {
+			return &TokenIntrospection{Active: false}, nil
+		}
+
+		return nil, err
+	}
+
+	// an access token outlives neither its session nor its user
+	session, err := s.SessionRepository.GetByID(ctx, principal.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return &TokenIntrospection{Active: false}, nil
+		}
+
+		return nil, err
+	}
+
+	if !session.Valid() {
+		return &TokenIntrospection{Active: false}, nil
+	}
+
+	introspection := &TokenIntrospection{
+		Active:    true,
+		TokenType: TokenTypeAccessToken,
+		TokenID:   principal.TokenID,
+		UserID:    principal.UserID,
+		SessionID: principal.SessionID,
+		Username:  principal.Username,
+		Scope:     strings.Join(principal.Roles, " "),
+		ExpiresAt: principal.ExpiresAt,
+	}
+
+	if principal.Claims.IssuedAt != nil {
+		introspection.IssuedAt = principal.Claims.IssuedAt.Time
+	}
+
+	return introspection, nil
+}
+
+func (s *OAuthService) introspectRefreshToken(ctx context.Context, token string) (*TokenIntrospection, error) {
+	session, err := s.SessionRepository.GetByRefreshTokenHash(ctx, models.HashRefreshToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return &TokenIntrospection{Active: false}, nil
+		}
+
+		return nil, err
+	}
+
+	if !session.Valid() {
+		return &TokenIntrospection{Active: false}, nil
+	}
+
+	user, err := s.UserRepository.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return &TokenIntrospection{Active: false}, nil
+		}
+
+		return nil, err
+	}
+
+	return &TokenIntrospection{
+		Active:    true,
+		TokenType: TokenTypeRefreshToken,
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Username:  user.Username,
+		Scope:     strings.Join(user.Roles, " "),
+		ExpiresAt: session.RefreshToken.ExpiredAt,
+	}, nil
+}
+
+// Revoke revokes a refresh token by revoking its session together with the
+// access tokens issued for it, or an access token by denylisting its ID until
+// it expires. Following RFC 7009, tokens that are
+// unknown, invalid or already revoked are not an error.
+func (s *OAuthService) Revoke(ctx context.Context, token string, tokenTypeHint string) error {
+	ctx, span := s.tracer.Start(ctx, "OAuthService.Revoke")
+	defer span.End()
+
+	revokers := []func(context.Context, string) (bool, error){
+		s.revokeAccessToken,
+		s.revokeRefreshToken,
+	}
+
+	if tokenTypeHint == TokenTypeRefreshToken {
+		revokers[0], revokers[1] = revokers[1], revokers[0]
+	}
+
+	for _, revoke := range revokers {
+		revoked, err := revoke(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		if revoked {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (s *OAuthService) revokeAccessToken(ctx context.Context, token string) (bool, error) {
+	principal, err := s.TokenVerifier.Verify(ctx, token)
+	if err != nil {
+		if verifier.IsInvalidToken(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	ttl := time.Until(principal.ExpiresAt) + denylistGracePeriod
+
+	if err := s.TokenDenylist.Add(ctx, principal.TokenID, ttl); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *OAuthService) revokeRefreshToken(ctx context.Context, token string) (bool, error) {
+	session, err := s.SessionRepository.GetByRefreshTokenHash(ctx, models.HashRefreshToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if _, err := s.SessionRepository.Revoke(ctx, session.ID); err != nil {
+		return false, err
+	}
+
+	if err := s.SessionDenylist.Add(ctx, session.ID.String(), s.cfg.AccessTokenTTL+denylistGracePeriod); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
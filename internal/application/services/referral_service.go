@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReferralCodeGenerator interface {
+	Generate() (string, error)
+}
+
+type ProfanityFilter interface {
+	Profane(s string) bool
+}
+
+type ReferralDependencies struct {
+	ReferralCodeRepository repository.ReferralCodeRepository
+	ReferralRepository     repository.ReferralRepository
+	UserRepository         repository.UserRepository
+	TransactionManager     repository.TransactionManager
+	LookupLimiter          repository.RateLimiter
+	CodeGenerator          ReferralCodeGenerator
+	ProfanityFilter        ProfanityFilter
+}
+
+func (d ReferralDependencies) Valid() error {
+	if d.ReferralCodeRepository == nil {
+		return errors.New("missing referral code repository")
+	}
+
+	if d.ReferralRepository == nil {
+		return errors.New("missing referral repository")
+	}
+
+	if d.UserRepository == nil {
+		return errors.New("missing user repository")
+	}
+
+	if d.LookupLimiter == nil {
+		return errors.New("missing lookup rate limiter")
+	}
+
+	if d.TransactionManager == nil {
+		return errors.New("missing transaction manager")
+	}
+
+	if d.CodeGenerator == nil {
+		return errors.New("missing referral code generator")
+	}
+
+	if d.ProfanityFilter == nil {
+		return errors.New("missing profanity filter")
+	}
+
+	return nil
+}
+
+var (
+	vanityCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{2,30}[A-Z0-9]$`)
+	campaignPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+)
+
+// generateAttempts bounds the generated codes tried when they are taken.
+const generateAttempts = 3
+
+type ReferralServiceConfig struct {
+	// CodeTTL is the lifetime of a code created without an expiry.
+	CodeTTL time.Duration
+	// MaxCodeTTL bounds the expiry a user may choose.
+	MaxCodeTTL time.Duration
+	// TreeMaxDepth bounds the levels of referral trees and statistics.
+	TreeMaxDepth int
+	// TreeMaxNodes bounds the referees returned in a tree.
+	TreeMaxNodes int
+}
+
+type ReferralService struct {
+	ReferralDependencies
+	cfg    ReferralServiceConfig
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewReferralService(d ReferralDependencies, cfg ReferralServiceConfig, log *slog.Logger, tracer trace.Tracer) (*ReferralService, error) {
+	if err := d.Valid(); err != nil {
+		return nil, errors.Wrap(err, "missing required dependency")
+	}
+
+	return &ReferralService{
+		ReferralDependencies: d,
+		cfg:                  cfg,
+		log:                  log,
+		tracer:               tracer,
+	}, nil
+}
+
+// ReferralCodeOptions are the choices a user may make for a new code, zero
+// values mean defaults.
+type ReferralCodeOptions struct {
+	// Code is a vanity code, a short one is generated when it is empty.
+	Code           string
+	Campaign       string
+	MaxRedemptions int
+	ExpiredAt      time.Time
+}
+
+// CreateCode creates a referral code for the user, who may have one active
+// code at a time; an exhausted code is replaced.
+func (s *ReferralService) CreateCode(ctx context.Context, userID uuid.UUID, opts ReferralCodeOptions) (*models.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.CreateCode")
+	defer span.End()
+
+	code, err := s.newCode(userID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdCode *models.ReferralCode
+
+	if err := s.TransactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		activeCode, err := s.ReferralCodeRepository.GetByUserID(ctx, userID)
+		if err == nil && !activeCode.Exhausted() {
+			return ErrReferralCodeExists
+		}
+
+		if err != nil && !errors.Is(err, repository.ErrReferralCodeNotFound) {
+			return err
+		}
+
+		// an expired or exhausted code still holds the user's slot until it
+		// is deleted
+		if err := s.ReferralCodeRepository.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+
+		createdCode, err = s.createCode(ctx, code, opts.Code == "")
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return createdCode, nil
+}
+
+func (s *ReferralService) newCode(userID uuid.UUID, opts ReferralCodeOptions) (*models.ReferralCode, error) {
+	now := time.Now()
+
+	code := &models.ReferralCode{
+		UserID:         userID,
+		Code:           models.NormalizeReferralCode(opts.Code),
+		Campaign:       strings.ToLower(strings.TrimSpace(opts.Campaign)),
+		MaxRedemptions: opts.MaxRedemptions,
+		ExpiredAt:      opts.ExpiredAt,
+	}
+
+	if code.ExpiredAt.IsZero() {
+		code.ExpiredAt = now.Add(s.cfg.CodeTTL)
+	}
+
+	if !code.ExpiredAt.After(now) || code.ExpiredAt.After(now.Add(s.cfg.MaxCodeTTL)) {
+		return nil, ErrInvalidReferralCodeExpiry
+	}
+
+	if code.MaxRedemptions < 0 {
+		return nil, ErrInvalidMaxRedemptions
+	}
+
+	if code.Campaign != "" && !campaignPattern.MatchString(code.Campaign) {
+		return nil, ErrInvalidCampaign
+	}
+
+	if code.Code == "" {
+		return code, nil
+	}
+
+	if !vanityCodePattern.MatchString(code.Code) {
+		return nil, ErrInvalidVanityCode
+	}
+
+	if s.ProfanityFilter.Profane(code.Code) {
+		return nil, ErrProfaneReferralCode
+	}
+
+	return code, nil
+}
+
+// createCode stores the code. A generated code that is taken is generated
+// anew, a taken vanity code is reported.
+func (s *ReferralService) createCode(ctx context.Context, code *models.ReferralCode, generate bool) (*models.ReferralCode, error) {
+	for attempt := 1; ; attempt++ {
+		if generate {
+			generatedCode, err := s.CodeGenerator.Generate()
+			if err != nil {
+				return nil, err
+			}
+
+			code.Code = generatedCode
+		}
+
+		createdCode, err := s.ReferralCodeRepository.Create(ctx, code)
+		switch {
+		case err == nil:
+			return createdCode, nil
+		case errors.Is(err, repository.ErrReferralCodeTaken):
+			if !generate || attempt == generateAttempts {
+				return nil, ErrReferralCodeTaken
+			}
+		case errors.Is(err, repository.ErrReferralCodeExists):
+			return nil, ErrReferralCodeExists
+		default:
+			return nil, err
+		}
+	}
+}
+
+func (s *ReferralService) GetCode(ctx context.Context, userID uuid.UUID) (*models.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.GetCode")
+	defer span.End()
+
+	code, err := s.ReferralCodeRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrReferralCodeNotFound) {
+			return nil, ErrReferralCodeNotFound
+		}
+
+		return nil, err
+	}
+
+	return code, nil
+}
+
+func (s *ReferralService) DeleteCode(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.DeleteCode")
+	defer span.End()
+
+	code, err := s.GetCode(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.ReferralCodeRepository.Delete(ctx, code.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LookupCode finds the active code of a discoverable user by username, or by
+// email when one is given. Lookups are rate limited per client.
+func (s *ReferralService) LookupCode(ctx context.Context, client string, username string, email string) (*models.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.LookupCode")
+	defer span.End()
+
+	allowed, err := s.LookupLimiter.Allow(ctx, "referral_lookup:"+client)
+	if err != nil {
+		// the limit is not worth failing lookups over
+		s.log.Warn("rate limit referral lookup", slog.String("error", err.Error()))
+	} else if !allowed {
+		return nil, ErrRateLimited
+	}
+
+	var code *models.ReferralCode
+
+	if email != "" {
+		code, err = s.ReferralCodeRepository.GetByVerifiedEmail(ctx, email)
+	} else {
+		code, err = s.ReferralCodeRepository.GetByUsername(ctx, username)
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrReferralCodeNotFound) {
+			return nil, ErrReferralCodeNotFound
+		}
+
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// SetDiscoverable sets whether the user's code can be looked up by others.
+func (s *ReferralService) SetDiscoverable(ctx context.Context, userID uuid.UUID, discoverable bool) error {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.SetDiscoverable")
+	defer span.End()
+
+	return s.TransactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.UserRepository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		user.ReferralDiscoverable = discoverable
+
+		_, err = s.UserRepository.Update(ctx, user)
+
+		return err
+	})
+}
+
+// ListReferees lists the users the referrer brought in directly.
+func (s *ReferralService) ListReferees(ctx context.Context, referrerID uuid.UUID, limit int, offset int) ([]models.Referee, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.ListReferees")
+	defer span.End()
+
+	return s.ReferralRepository.ListReferees(ctx, referrerID, limit, offset)
+}
+
+// Stats counts the referees of the referrer per level of the tree.
+func (s *ReferralService) Stats(ctx context.Context, referrerID uuid.UUID) (models.ReferralStats, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.Stats")
+	defer span.End()
+
+	return s.ReferralRepository.Stats(ctx, referrerID, s.cfg.TreeMaxDepth)
+}
+
+// Tree lists the referees of the referrer down to the given depth, which is
+// capped by the configured maximum; 0 means the maximum. The tree is
+// truncated when it has more than the configured number of nodes.
+func (s *ReferralService) Tree(ctx context.Context, referrerID uuid.UUID, depth int) (referees []models.Referee, truncated bool, err error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.Tree")
+	defer span.End()
+
+	if depth <= 0 || depth > s.cfg.TreeMaxDepth {
+		depth = s.cfg.TreeMaxDepth
+	}
+
+	referees, err = s.ReferralRepository.Tree(ctx, referrerID, depth, s.cfg.TreeMaxNodes+1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(referees) > s.cfg.TreeMaxNodes {
+		return referees[:s.cfg.TreeMaxNodes], true, nil
+	}
+
+	return referees, false, nil
+}
+
+// RedemptionsByCampaign counts the registrations with referral codes per
+// campaign between from and to.
+func (s *ReferralService) RedemptionsByCampaign(ctx context.Context, from time.Time, to time.Time) ([]models.CampaignRedemptions, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.RedemptionsByCampaign")
+	defer span.End()
+
+	return s.ReferralRepository.RedemptionsByCampaign(ctx, from, to)
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
@@ -12,25 +13,30 @@ import (
 )
 
 type TokenManager interface {
-	NewAccessToken() (models.AccessToken, error)
+	NewAccessToken(user *models.User, session *models.Session) (models.AccessToken, error)
 	NewRefreshToken() (models.RefreshToken, error)
 }
 
+type SecurityEventEmitter interface {
+	Emit(ctx context.Context, event models.SecurityEvent)
+}
+
 type PasswordManager interface {
 	HashPassword(password string) (string, error)
 	CheckPassword(password string, hashPassword string) bool
 }
 
-const redisTTL = time.Hour * 60
-
 type Dependencies struct {
-	UserRepository     repository.UserRepository
-	SessionRepository  repository.SessionRepository
-	UserCache          repository.UserCache
-	SessionCache       repository.SessionCache
-	TransactionManager repository.TransactionManager
-	TokenManager       TokenManager
-	PasswordManager    PasswordManager
+	UserRepository         repository.UserRepository
+	SessionRepository      repository.SessionRepository
+	ReferralCodeRepository repository.ReferralCodeRepository
+	OutboxRepository       repository.OutboxRepository
+	TokenDenylist          repository.TokenDenylist
+	SessionDenylist        repository.SessionDenylist
+	TransactionManager     repository.TransactionManager
+	TokenManager           TokenManager
+	PasswordManager        PasswordManager
+	SecurityEvents         SecurityEventEmitter
 }
 
 func (d Dependencies) Valid() error {
@@ -42,6 +48,14 @@ func (d Dependencies) Valid() error {
 		return errors.New("missing session repository")
 	}
 
+	if d.ReferralCodeRepository == nil {
+		return errors.New("missing referral code repository")
+	}
+
+	if d.OutboxRepository == nil {
+		return errors.New("missing outbox repository")
+	}
+
 	if d.TransactionManager == nil {
 		return errors.New("missing transaction manager")
 	}
@@ -50,35 +64,51 @@ func (d Dependencies) Valid() error {
 		return errors.New("missing password manager")
 	}
 
-	if d.UserCache == nil {
-		return errors.New("missing user cache")
+	if d.SecurityEvents == nil {
+		return errors.New("missing security event emitter")
 	}
 
-	if d.SessionCache == nil {
-		return errors.New("missing session cache")
+	if d.TokenDenylist == nil {
+		return errors.New("missing token denylist")
+	}
+
+	if d.SessionDenylist == nil {
+		return errors.New("missing session denylist")
 	}
 
 	return nil
 }
 
+type UserServiceConfig struct {
+	SessionPolicy models.SessionPolicy
+	SessionLimits models.SessionLimits
+	// AccessTokenTTL bounds how long a revoked session stays denylisted.
+	AccessTokenTTL time.Duration
+}
+
 type UserService struct {
 	Dependencies
+	cfg    UserServiceConfig
 	log    *slog.Logger
 	tracer trace.Tracer
 }
 
-func NewUserService(d Dependencies, log *slog.Logger, tracer trace.Tracer) (*UserService, error) {
+func NewUserService(d Dependencies, cfg UserServiceConfig, log *slog.Logger, tracer trace.Tracer) (*UserService, error) {
 	if err := d.Valid(); err != nil {
 		return nil, errors.Wrap(err, "missing required dependency")
 	}
 
 	return &UserService{
 		Dependencies: d,
+		cfg:          cfg,
 		log:          log,
 		tracer:       tracer,
 	}, nil
 }
 
+// Register creates a user. A non-empty referral code must be an active one
+// with redemptions left, its owner is recorded as the referrer of the user,
+// the redemption in the ledger and a ReferralRedeemed event in the outbox.
 func (s *UserService) Register(ctx context.Context, username string, password string, referralCode string) (*models.User, error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.Register")
 	defer span.End()
@@ -91,49 +121,87 @@ func (s *UserService) Register(ctx context.Context, username string, password st
 	user := models.User{
 		Username:     username,
 		HashPassword: hashPassword,
+		Roles:        []string{models.RoleUser},
+		// discoverability is opt-out
+		ReferralDiscoverable: true,
 	}
 
-	createdUser, err := s.UserRepository.Create(ctx, &user)
-	if err != nil {
-		return nil, err
-	}
+	var createdUser *models.User
 
-	s.UserCache.Set(ctx, username, *createdUser, redisTTL)
+	if err := s.TransactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		var code *models.ReferralCode
 
-	return createdUser, nil
-}
+		if referralCode != "" {
+			code, err = s.referralCode(ctx, referralCode)
+			if err != nil {
+				return err
+			}
 
-func (s *UserService) Login(ctx context.Context, username string, password string) (at string, rt string, err error) {
-	ctx, span := s.tracer.Start(ctx, "UserService.Login")
-	defer span.End()
+			user.ReferrerID = &code.UserID
+		}
 
-	getUser := func(username string) (*models.User, error) {
-		if cacheUser, err := s.UserCache.Get(ctx, username); err == nil {
-			return &cacheUser, nil
+		createdUser, err = s.UserRepository.Create(ctx, &user)
+		if err != nil || code == nil {
+			return err
 		}
 
-		dbUser, err := s.UserRepository.GetByUsername(ctx, username)
+		redemption, err := s.ReferralCodeRepository.Redeem(ctx, code.ID, createdUser.ID)
 		if err != nil {
-			return nil, err
+			if errors.Is(err, repository.ErrReferralCodeExhausted) {
+				// redeemed by concurrent registrations in the meantime
+				return ErrReferralCodeExhausted
+			}
+
+			return err
 		}
 
-		return dbUser, nil
+		return s.OutboxRepository.AddReferralRedeemed(ctx, models.ReferralRedeemed{
+			ReferrerID: redemption.ReferrerID,
+			RefereeID:  redemption.RefereeID,
+			Code:       redemption.Code,
+			RedeemedAt: redemption.RedeemedAt,
+		})
+	}); err != nil {
+		return nil, err
 	}
 
-	user, err := getUser(username)
+	return createdUser, nil
+}
+
+func (s *UserService) referralCode(ctx context.Context, referralCode string) (*models.ReferralCode, error) {
+	code, err := s.ReferralCodeRepository.GetByCode(ctx, models.NormalizeReferralCode(referralCode))
 	if err != nil {
-		return "", "", err
+		if errors.Is(err, repository.ErrReferralCodeNotFound) {
+			return nil, ErrInvalidReferralCode
+		}
+
+		return nil, err
 	}
 
-	if !s.PasswordManager.CheckPassword(password, user.HashPassword) {
-		return "", "", ErrInvalidPassword
+	if code.Exhausted() {
+		return nil, ErrReferralCodeExhausted
 	}
 
-	accessToken, err := s.TokenManager.NewAccessToken()
+	return code, nil
+}
+
+func (s *UserService) Login(ctx context.Context, username string, password string, clientInfo models.ClientInfo) (at string, rt string, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.Login")
+	defer span.End()
+
+	user, err := s.UserRepository.GetByUsername(ctx, username)
 	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return "", "", ErrInvalidPassword
+		}
+
 		return "", "", err
 	}
 
+	if !s.PasswordManager.CheckPassword(password, user.HashPassword) {
+		return "", "", ErrInvalidPassword
+	}
+
 	refreshToken, err := s.TokenManager.NewRefreshToken()
 	if err != nil {
 		return "", "", err
@@ -142,54 +210,124 @@ func (s *UserService) Login(ctx context.Context, username string, password strin
 	session := &models.Session{
 		UserID:       user.ID,
 		RefreshToken: refreshToken,
+		ClientInfo:   clientInfo,
+	}
+
+	session.LimitRefreshToken(&session.RefreshToken, s.cfg.SessionLimits, time.Now())
+
+	var (
+		createdSession  *models.Session
+		evictedSessions []models.Session
+	)
+
+	if err := s.TransactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		createdSession, err = s.SessionRepository.Create(ctx, session)
+		if err != nil {
+			return err
+		}
+
+		if s.cfg.SessionPolicy.Unlimited() {
+			return nil
+		}
+
+		// the new session is the most recent one, so it is always kept
+		evictedSessions, err = s.SessionRepository.RevokeOldestByUserID(ctx, user.ID, s.cfg.SessionPolicy.MaxSessions)
+
+		return err
+	}); err != nil {
+		return "", "", err
 	}
 
-	if err := s.SessionRepository.RevokeByUserID(ctx, user.ID); err != nil {
+	if err := s.denylistSessions(ctx, evictedSessions...); err != nil {
 		return "", "", err
 	}
 
-	if _, err := s.SessionRepository.Create(ctx, session); err != nil {
+	accessToken, err := s.TokenManager.NewAccessToken(user, createdSession)
+	if err != nil {
 		return "", "", err
 	}
 
 	at = accessToken.Token
 	rt = refreshToken.Token
 
-	s.UserCache.Set(ctx, username, *user, redisTTL)
-
 	return at, rt, nil
 }
 
-func (s *UserService) Refresh(ctx context.Context, refreshToken string) (at string, rt string, err error) {
+func (s *UserService) Refresh(ctx context.Context, refreshToken string, clientInfo models.ClientInfo) (at string, rt string, err error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.Refresh")
 	defer span.End()
 
-	newAccessToken, err := s.TokenManager.NewAccessToken()
+	refreshTokenHash := models.HashRefreshToken(refreshToken)
+
+	// the session may be served from a cache; a stale one is evicted by the
+	// failed rotation, so the second attempt reads the database
+	at, rt, err = s.refresh(ctx, refreshTokenHash, clientInfo)
+	if errors.Is(err, repository.ErrStaleSession) {
+		at, rt, err = s.refresh(ctx, refreshTokenHash, clientInfo)
+	}
+
+	if errors.Is(err, repository.ErrStaleSession) {
+		// rotated or revoked by a concurrent request
+		return "", "", ErrUnauthorizedRefresh
+	}
+
+	return at, rt, err
+}
+
+func (s *UserService) refresh(ctx context.Context, refreshTokenHash string, clientInfo models.ClientInfo) (at string, rt string, err error) {
+	newRefreshToken, err := s.TokenManager.NewRefreshToken()
 	if err != nil {
 		return "", "", err
 	}
 
-	newRefreshToken, err := s.TokenManager.NewRefreshToken()
+	// the session may come from the cache, Rotate checks it against the
+	// database
+	currentSession, err := s.SessionRepository.GetByRefreshTokenHash(ctx, refreshTokenHash)
 	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return "", "", s.detectReuse(ctx, refreshTokenHash)
+		}
+
 		return "", "", err
 	}
 
-	at = newAccessToken.Token
-	rt = newRefreshToken.Token
+	now := time.Now()
+
+	if !currentSession.ValidWithin(s.cfg.SessionLimits, now) {
+		return "", "", ErrUnauthorizedRefresh
+	}
+
+	currentSession.LimitRefreshToken(&newRefreshToken, s.cfg.SessionLimits, now)
+
+	nextSession := *currentSession
+	nextSession.RefreshToken = newRefreshToken
+	nextSession.LastUsedAt = now
+	nextSession.ClientInfo = clientInfo
+
+	var newAccessToken models.AccessToken
 
 	if err := s.TransactionManager.WithTransaction(ctx, func(ctx context.Context) error {
-		session, err := s.SessionRepository.GetByRefreshToken(ctx, refreshToken)
+		rotatedSession, err := s.SessionRepository.Rotate(ctx, &nextSession, refreshTokenHash)
 		if err != nil {
-			return ErrUnauthorizedRefresh
+			return err
 		}
 
-		if !session.Valid() {
-			return ErrUnauthorizedRefresh
+		if err := s.SessionRepository.AddRotatedRefreshToken(ctx, rotatedSession.ID, refreshTokenHash); err != nil {
+			return err
 		}
 
-		session.RefreshToken = newRefreshToken
+		user, err := s.UserRepository.GetByID(ctx, rotatedSession.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				// the user was deleted
+				return ErrUnauthorizedRefresh
+			}
+
+			return err
+		}
 
-		if _, err := s.SessionRepository.Update(ctx, session); err != nil {
+		newAccessToken, err = s.TokenManager.NewAccessToken(user, rotatedSession)
+		if err != nil {
 			return err
 		}
 
@@ -198,5 +336,142 @@ func (s *UserService) Refresh(ctx context.Context, refreshToken string) (at stri
 		return "", "", err
 	}
 
+	at = newAccessToken.Token
+	rt = newRefreshToken.Token
+
 	return at, rt, nil
 }
+
+// detectReuse handles an unknown refresh token. A token that was already
+// rotated out means it leaked, so the whole token family is revoked.
+func (s *UserService) detectReuse(ctx context.Context, refreshTokenHash string) error {
+	reusedSession, err := s.SessionRepository.GetByRotatedRefreshToken(ctx, refreshTokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrUnauthorizedRefresh
+		}
+
+		return err
+	}
+
+	if _, err := s.SessionRepository.Revoke(ctx, reusedSession.ID); err != nil {
+		return err
+	}
+
+	if err := s.denylistSessions(ctx, *reusedSession); err != nil {
+		return err
+	}
+
+	s.SecurityEvents.Emit(ctx, models.SecurityEvent{
+		Type:       models.SecurityEventRefreshTokenReuse,
+		UserID:     reusedSession.UserID,
+		SessionID:  reusedSession.ID,
+		OccurredAt: time.Now(),
+	})
+
+	return ErrRefreshTokenReused
+}
+
+func (s *UserService) ListSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.ListSessions")
+	defer span.End()
+
+	return s.SessionRepository.ListActiveByUserID(ctx, userID)
+}
+
+// RevokeSession revokes one of the user's sessions together with the access
+// tokens issued for it. Sessions of other users are reported as not found.
+func (s *UserService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "UserService.RevokeSession")
+	defer span.End()
+
+	session, err := s.SessionRepository.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+
+		return err
+	}
+
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	if _, err := s.SessionRepository.Revoke(ctx, session.ID); err != nil {
+		return err
+	}
+
+	return s.denylistSessions(ctx, *session)
+}
+
+// LogoutAll revokes every session of the user together with the access tokens
+// issued for them.
+func (s *UserService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "UserService.LogoutAll")
+	defer span.End()
+
+	sessions, err := s.SessionRepository.RevokeByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.denylistSessions(ctx, sessions...)
+}
+
+// Logout ends the session of the presented refresh token, or the session the
+// access token was issued for when no refresh token is given, and denylists
+// the access token so it stops working right away.
+func (s *UserService) Logout(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, accessToken models.AccessToken, refreshToken string) error {
+	ctx, span := s.tracer.Start(ctx, "UserService.Logout")
+	defer span.End()
+
+	var (
+		session *models.Session
+		err     error
+	)
+
+	if refreshToken != "" {
+		session, err = s.SessionRepository.GetByRefreshTokenHash(ctx, models.HashRefreshToken(refreshToken))
+	} else {
+		session, err = s.SessionRepository.GetByID(ctx, sessionID)
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+
+		return err
+	}
+
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	if _, err := s.SessionRepository.Revoke(ctx, session.ID); err != nil {
+		return err
+	}
+
+	if err := s.TokenDenylist.Add(ctx, accessToken.ID, time.Until(accessToken.ExpiredAt)+denylistGracePeriod); err != nil {
+		return err
+	}
+
+	// other access tokens of the session, e.g. ones issued before a refresh
+	return s.denylistSessions(ctx, *session)
+}
+
+// denylistSessions makes every access token issued for the sessions invalid.
+// Access tokens are not tracked, so the session stays denylisted for as long
+// as the last token issued for it can live.
+func (s *UserService) denylistSessions(ctx context.Context, sessions ...models.Session) error {
+	ttl := s.cfg.AccessTokenTTL + denylistGracePeriod
+
+	for _, session := range sessions {
+		if err := s.SessionDenylist.Add(ctx, session.ID.String(), ttl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+type OutboxRelayConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// OutboxRelay periodically publishes the events stored in the outbox and
+// deletes them once published. Events are delivered at least once: a relay
+// that stops between publishing and committing the deletion publishes the
+// event again. Replicas relay different events, as each locks its batch.
+type OutboxRelay struct {
+	cfg                OutboxRelayConfig
+	outboxRepository   repository.OutboxRepository
+	transactionManager repository.TransactionManager
+	publisher          EventPublisher
+	published          metric.Int64Counter
+	log                *slog.Logger
+	tracer             trace.Tracer
+}
+
+func NewOutboxRelay(
+	cfg OutboxRelayConfig,
+	outboxRepository repository.OutboxRepository,
+	transactionManager repository.TransactionManager,
+	publisher EventPublisher,
+	meter metric.Meter,
+	log *slog.Logger,
+	tracer trace.Tracer,
+) (*OutboxRelay, error) {
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s", cfg.Interval)
+	}
+
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d", cfg.BatchSize)
+	}
+
+	published, err := meter.Int64Counter(
+		"auth_outbox_events_published",
+		metric.WithDescription("Number of domain events published from the outbox"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxRelay{
+		cfg:                cfg,
+		outboxRepository:   outboxRepository,
+		transactionManager: transactionManager,
+		publisher:          publisher,
+		published:          published,
+		log:                log,
+		tracer:             tracer,
+	}, nil
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.relay(ctx); err != nil && ctx.Err() == nil {
+				r.log.Error("relay outbox events", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) relay(ctx context.Context) error {
+	ctx, span := r.tracer.Start(ctx, "OutboxRelay.relay")
+	defer span.End()
+
+	for ctx.Err() == nil {
+		var (
+			pending    int
+			published  []uuid.UUID
+			publishErr error
+		)
+
+		if err := r.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+			events, err := r.outboxRepository.ListPending(ctx, r.cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+
+			pending = len(events)
+
+			// events are published in order and the ones published before a
+			// failure are still deleted, the rest is retried on the next tick
+			published = make([]uuid.UUID, 0, len(events))
+			for _, event := range events {
+				if publishErr = r.publisher.Publish(ctx, event); publishErr != nil {
+					break
+				}
+
+				published = append(published, event.ID)
+			}
+
+			if len(published) == 0 {
+				return nil
+			}
+
+			return r.outboxRepository.Delete(ctx, published)
+		}); err != nil {
+			return err
+		}
+
+		r.published.Add(ctx, int64(len(published)))
+
+		if publishErr != nil {
+			return publishErr
+		}
+
+		if pending < r.cfg.BatchSize {
+			return nil
+		}
+	}
+
+	return ctx.Err()
+}
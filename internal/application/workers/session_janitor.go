@@ -0,0 +1,134 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sessionJanitorLockKey is the advisory lock that elects the replica running
+// the janitor. It must not be reused by any other job.
+const sessionJanitorLockKey int64 = 1001
+
+type SessionJanitorConfig struct {
+	Interval  time.Duration
+	Retention time.Duration
+	BatchSize int
+}
+
+// SessionJanitor periodically deletes sessions that expired, or were revoked,
+// longer than the retention window ago. Only the replica holding the advisory
+// lock purges a batch, the others skip it.
+type SessionJanitor struct {
+	cfg                SessionJanitorConfig
+	sessionRepository  repository.SessionRepository
+	transactionManager repository.TransactionManager
+	locker             repository.Locker
+	purged             metric.Int64Counter
+	log                *slog.Logger
+	tracer             trace.Tracer
+}
+
+func NewSessionJanitor(
+	cfg SessionJanitorConfig,
+	sessionRepository repository.SessionRepository,
+	transactionManager repository.TransactionManager,
+	locker repository.Locker,
+	meter metric.Meter,
+	log *slog.Logger,
+	tracer trace.Tracer,
+) (*SessionJanitor, error) {
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d", cfg.BatchSize)
+	}
+
+	purged, err := meter.Int64Counter(
+		"auth_sessions_purged",
+		metric.WithDescription("Number of expired or revoked sessions deleted by the janitor"),
+		metric.WithUnit("{session}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionJanitor{
+		cfg:                cfg,
+		sessionRepository:  sessionRepository,
+		transactionManager: transactionManager,
+		locker:             locker,
+		purged:             purged,
+		log:                log,
+		tracer:             tracer,
+	}, nil
+}
+
+func (j *SessionJanitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.purge(ctx); err != nil && ctx.Err() == nil {
+				j.log.Error("purge sessions", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (j *SessionJanitor) purge(ctx context.Context) error {
+	ctx, span := j.tracer.Start(ctx, "SessionJanitor.purge")
+	defer span.End()
+
+	before := time.Now().Add(-j.cfg.Retention)
+
+	var total int64
+	defer func() {
+		if total > 0 {
+			j.log.Info("sessions purged", slog.Int64("count", total))
+		}
+	}()
+
+	// every batch is its own short transaction, so the janitor never holds
+	// locks on the session table for long
+	for ctx.Err() == nil {
+		var (
+			locked bool
+			purged int64
+		)
+
+		if err := j.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+			var err error
+
+			locked, err = j.locker.TryLock(ctx, sessionJanitorLockKey)
+			if err != nil || !locked {
+				return err
+			}
+
+			purged, err = j.sessionRepository.Purge(ctx, before, j.cfg.BatchSize)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		if !locked {
+			return nil
+		}
+
+		total += purged
+		j.purged.Add(ctx, purged)
+
+		if purged < int64(j.cfg.BatchSize) {
+			return nil
+		}
+	}
+
+	return ctx.Err()
+}
@@ -4,30 +4,54 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 type AccessToken struct {
-	Token string
+	ID        string
+	Token     string
+	ExpiredAt time.Time
 }
 
-func NewAccessToken(ttl time.Duration, secretKey []byte) (AccessToken, error) {
-	expiredAt := time.Now().Add(ttl)
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	SessionID string   `json:"sid"`
+	Username  string   `json:"username"`
+	Roles     []string `json:"roles,omitempty"`
+}
 
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(expiredAt),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Issuer:    "auth-service",
+func NewAccessTokenClaims(user *User, session *Session, issuer string, audience []string, ttl time.Duration) AccessTokenClaims {
+	now := time.Now()
+
+	return AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID.String(),
+			Issuer:    issuer,
+			Audience:  audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		SessionID: session.ID.String(),
+		Username:  user.Username,
+		Roles:     user.Roles,
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+func NewAccessToken(claims AccessTokenClaims, keyID string, method jwt.SigningMethod, signingKey any) (AccessToken, error) {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyID
 
-	signedToken, err := token.SignedString(secretKey)
+	signedToken, err := token.SignedString(signingKey)
 	if err != nil {
 		return AccessToken{}, err
 	}
 
 	at := AccessToken{
-		Token: signedToken,
+		ID:        claims.ID,
+		Token:     signedToken,
+		ExpiredAt: claims.ExpiresAt.Time,
 	}
 
 	return at, nil
@@ -0,0 +1,8 @@
+package models
+
+// Client is a confidential OAuth client, such as the API gateway, that is
+// allowed to call the token introspection and revocation endpoints.
+type Client struct {
+	ID         string
+	SecretHash string
+}
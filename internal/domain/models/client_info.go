@@ -0,0 +1,8 @@
+package models
+
+// ClientInfo describes the device and network a session is used from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+	Device    string
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DomainEventReferralRedeemed = "referral_redeemed"
+
+// ReferralRedeemed is emitted when a user registers with a referral code, so
+// that the referrer can be credited.
+type ReferralRedeemed struct {
+	ReferrerID uuid.UUID
+	RefereeID  uuid.UUID
+	Code       string
+	RedeemedAt time.Time
+}
+
+// OutboxEvent is a domain event stored in the outbox until it is published.
+// Payload is its JSON encoding.
+type OutboxEvent struct {
+	ID         uuid.UUID
+	Type       string
+	Payload    []byte
+	OccurredAt time.Time
+}
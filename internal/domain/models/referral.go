@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Referee is a user brought in by a referrer, directly at depth 1 or through
+// other referees below that.
+type Referee struct {
+	UserID       uuid.UUID
+	ReferrerID   uuid.UUID
+	Username     string
+	RegisteredAt time.Time
+	Depth        int
+}
+
+type ReferralStats struct {
+	// Levels holds the number of referees per depth, starting at depth 1.
+	Levels []int64
+}
+
+func (s ReferralStats) Direct() int64 {
+	if len(s.Levels) == 0 {
+		return 0
+	}
+
+	return s.Levels[0]
+}
+
+func (s ReferralStats) Total() int64 {
+	var total int64
+	for _, count := range s.Levels {
+		total += count
+	}
+
+	return total
+}
+
+// CampaignRedemptions counts the referees a campaign brought in. Codes
+// without a campaign are counted under an empty one.
+type CampaignRedemptions struct {
+	Campaign    string
+	Redemptions int64
+	Referrers   int64
+}
@@ -1,17 +1,47 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type ReferralCode struct {
-	ID        uuid.UUID
-	UserID    uuid.UUID
-	ExpiredAt time.Time
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// Code is what users share and enter, in its normalized form.
+	Code     string
+	Campaign string
+	// MaxRedemptions limits how many users can register with the code, 0
+	// means no limit.
+	MaxRedemptions int
+	Redemptions    int
+	CreatedAt      time.Time
+	ExpiredAt      time.Time
 }
 
 func (t *ReferralCode) Valid() bool {
-	return t.ExpiredAt.After(time.Now())
+	return t.ExpiredAt.After(time.Now()) && !t.Exhausted()
+}
+
+func (t *ReferralCode) Exhausted() bool {
+	return t.MaxRedemptions > 0 && t.Redemptions >= t.MaxRedemptions
+}
+
+// NormalizeReferralCode returns the form codes are stored in, so that they
+// can be entered regardless of case.
+func NormalizeReferralCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ReferralRedemption records the registration of a referee with a code.
+type ReferralRedemption struct {
+	ID             uuid.UUID
+	ReferralCodeID uuid.UUID
+	ReferrerID     uuid.UUID
+	RefereeID      uuid.UUID
+	Code           string
+	Campaign       string
+	RedeemedAt     time.Time
 }
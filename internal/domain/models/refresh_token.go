@@ -2,14 +2,23 @@ package models
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"time"
 )
 
-const tokenLength = 255
+const (
+	tokenLength       = 255
+	tokenPrefixLength = 8
+)
 
+// RefreshToken is an opaque token. Only its hash is persisted; Token holds the
+// plaintext right after issuing and is empty for tokens loaded from storage.
 type RefreshToken struct {
 	Token     string
+	Hash      string
+	Prefix    string
 	ExpiredAt time.Time
 	IsRevoked bool
 }
@@ -22,6 +31,8 @@ func NewRefreshToken(ttl time.Duration) (RefreshToken, error) {
 
 	rt := RefreshToken{
 		Token:     token,
+		Hash:      HashRefreshToken(token),
+		Prefix:    token[:tokenPrefixLength],
 		ExpiredAt: time.Now().Add(ttl),
 	}
 
@@ -32,6 +43,12 @@ func (t *RefreshToken) Valid() bool {
 	return t.ExpiredAt.After(time.Now()) && !t.IsRevoked
 }
 
+// HashRefreshToken returns the hex encoded SHA-256 digest of the token.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func generateRandomString(length int) (string, error) {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
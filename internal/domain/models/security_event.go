@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+
+type SecurityEvent struct {
+	Type       string
+	UserID     uuid.UUID
+	SessionID  uuid.UUID
+	OccurredAt time.Time
+}
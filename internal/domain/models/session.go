@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -8,8 +10,61 @@ type Session struct {
 	ID           uuid.UUID
 	UserID       uuid.UUID
 	RefreshToken RefreshToken
+	CreatedAt    time.Time
+	LastUsedAt   time.Time
+	ClientInfo   ClientInfo
+}
+
+// SessionLimits bound the life of a session independently of the refresh
+// token TTL. IdleTimeout ends a session that was not refreshed for that long,
+// MaxLifetime ends it that long after login however often it is refreshed.
+// Zero disables a limit.
+type SessionLimits struct {
+	IdleTimeout time.Duration
+	MaxLifetime time.Duration
 }
 
 func (s *Session) Valid() bool {
 	return s.RefreshToken.Valid()
 }
+
+func (s *Session) ValidWithin(limits SessionLimits, now time.Time) bool {
+	if !s.Valid() {
+		return false
+	}
+
+	if limits.IdleTimeout > 0 && now.Sub(s.LastUsedAt) > limits.IdleTimeout {
+		return false
+	}
+
+	if limits.MaxLifetime > 0 && now.Sub(s.CreatedAt) > limits.MaxLifetime {
+		return false
+	}
+
+	return true
+}
+
+// LimitRefreshToken shortens the expiry of a refresh token issued for the
+// session at now, so it cannot outlive the idle timeout or the max lifetime.
+func (s *Session) LimitRefreshToken(refreshToken *RefreshToken, limits SessionLimits, now time.Time) {
+	if limits.IdleTimeout > 0 {
+		refreshToken.ExpiredAt = earliest(refreshToken.ExpiredAt, now.Add(limits.IdleTimeout))
+	}
+
+	if limits.MaxLifetime > 0 {
+		createdAt := s.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+
+		refreshToken.ExpiredAt = earliest(refreshToken.ExpiredAt, createdAt.Add(limits.MaxLifetime))
+	}
+}
+
+func earliest(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
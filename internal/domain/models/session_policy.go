@@ -0,0 +1,36 @@
+package models
+
+import "fmt"
+
+const (
+	SessionPolicySingle    = "single"
+	SessionPolicyLimited   = "limited"
+	SessionPolicyUnlimited = "unlimited"
+)
+
+// SessionPolicy limits how many active sessions a user may have at once.
+// When a login exceeds the limit, the oldest sessions are revoked.
+type SessionPolicy struct {
+	MaxSessions int
+}
+
+func NewSessionPolicy(policy string, maxSessions int) (SessionPolicy, error) {
+	switch policy {
+	case SessionPolicySingle:
+		return SessionPolicy{MaxSessions: 1}, nil
+	case SessionPolicyLimited:
+		if maxSessions < 1 {
+			return SessionPolicy{}, fmt.Errorf("max sessions must be positive for %q policy", policy)
+		}
+
+		return SessionPolicy{MaxSessions: maxSessions}, nil
+	case SessionPolicyUnlimited:
+		return SessionPolicy{}, nil
+	default:
+		return SessionPolicy{}, fmt.Errorf("unknown session policy %q", policy)
+	}
+}
+
+func (p SessionPolicy) Unlimited() bool {
+	return p.MaxSessions == 0
+}
@@ -4,8 +4,21 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID           uuid.UUID
 	Username     string
 	HashPassword string
+	Roles        []string
+	// ReferrerID is the user whose referral code was used to register, if any.
+	ReferrerID    *uuid.UUID
+	Email         string
+	EmailVerified bool
+	// ReferralDiscoverable allows others to look up the user's referral code
+	// by username or verified email.
+	ReferralDiscoverable bool
 }
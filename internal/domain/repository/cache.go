@@ -0,0 +1,12 @@
+package repository
+
+import "time"
+
+// CacheEntry is a cached value with what is needed to refresh it before it
+// expires. A Missing entry caches a lookup that found nothing.
+type CacheEntry[T any] struct {
+	Value     T             `json:"value"`
+	Missing   bool          `json:"missing,omitempty"`
+	Delta     time.Duration `json:"delta"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+)
+
+type ClientRepository interface {
+	GetByID(ctx context.Context, clientID string) (*models.Client, error)
+}
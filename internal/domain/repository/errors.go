@@ -0,0 +1,19 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrStaleSession is returned when a session was changed after it had been
+	// read, e.g. its refresh token was rotated or the session was revoked.
+	ErrStaleSession = errors.New("stale session")
+
+	ErrUserNotFound         = errors.New("user not found")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrReferralCodeNotFound = errors.New("referral code not found")
+	ErrReferralCodeExists   = errors.New("referral code exists")
+	// ErrReferralCodeTaken is returned when another code has the same string.
+	ErrReferralCodeTaken = errors.New("referral code taken")
+	// ErrReferralCodeExhausted is returned when a code can no longer be
+	// redeemed: it ran out of redemptions, expired or was deleted.
+	ErrReferralCodeExhausted = errors.New("referral code exhausted")
+)
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+)
+
+// Locker takes locks shared by all replicas. A lock is held until the end of
+// the transaction it was taken in, so TryLock must run inside one.
+type Locker interface {
+	TryLock(ctx context.Context, key int64) (bool, error)
+}
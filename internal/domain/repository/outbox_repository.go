@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+)
+
+// OutboxRepository stores domain events in the transaction that produces
+// them, so an event is published if and only if that transaction commits.
+type OutboxRepository interface {
+	AddReferralRedeemed(ctx context.Context, event models.ReferralRedeemed) error
+	// ListPending locks up to limit events, oldest first, skipping events
+	// locked by other relays. It must run within a transaction.
+	ListPending(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	Delete(ctx context.Context, eventIDs []uuid.UUID) error
+}
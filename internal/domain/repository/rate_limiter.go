@@ -0,0 +1,9 @@
+package repository
+
+import "context"
+
+type RateLimiter interface {
+	// Allow counts a request under the key and reports whether it is within
+	// the limit.
+	Allow(ctx context.Context, key string) (bool, error)
+}
@@ -8,10 +8,26 @@ import (
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
 )
 
+// ReferralCodeRepository stores referral codes. The getters only return codes
+// that are neither deleted nor expired; exhausted codes are returned.
 type ReferralCodeRepository interface {
+	// Create returns ErrReferralCodeTaken when the code string is in use and
+	// ErrReferralCodeExists when the user already has a code.
 	Create(ctx context.Context, referralCode *models.ReferralCode) (*models.ReferralCode, error)
-	Delete(ctx context.Context, referralCodeID uuid.UUID) (*time.Time, error)
+	GetByID(ctx context.Context, referralCodeID uuid.UUID) (*models.ReferralCode, error)
+	GetByCode(ctx context.Context, code string) (*models.ReferralCode, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.ReferralCode, error)
+	// GetByUsername and GetByVerifiedEmail only find codes of users that are
+	// referral discoverable.
 	GetByUsername(ctx context.Context, username string) (*models.ReferralCode, error)
+	GetByVerifiedEmail(ctx context.Context, email string) (*models.ReferralCode, error)
+	Delete(ctx context.Context, referralCodeID uuid.UUID) (*time.Time, error)
+	// DeleteByUserID deletes every code of the user, expired ones included.
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	// Redeem counts a redemption of the code by the referee and records it
+	// in the ledger. It returns ErrReferralCodeExhausted when the code can no
+	// longer be redeemed.
+	Redeem(ctx context.Context, referralCodeID uuid.UUID, refereeID uuid.UUID) (*models.ReferralRedemption, error)
 }
 
 type ReferralCodeCache interface {
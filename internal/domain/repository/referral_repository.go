@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+)
+
+// ReferralRepository reads the referral graph formed by users.referrer_id.
+type ReferralRepository interface {
+	// ListReferees lists the direct referees, most recently registered first.
+	ListReferees(ctx context.Context, referrerID uuid.UUID, limit int, offset int) ([]models.Referee, error)
+	// Tree lists referees down to maxDepth levels, by depth and registration.
+	Tree(ctx context.Context, referrerID uuid.UUID, maxDepth int, limit int) ([]models.Referee, error)
+	Stats(ctx context.Context, referrerID uuid.UUID, maxDepth int) (models.ReferralStats, error)
+	// RedemptionsByCampaign counts the redemptions in [from, to) per campaign.
+	RedemptionsByCampaign(ctx context.Context, from time.Time, to time.Time) ([]models.CampaignRedemptions, error)
+}
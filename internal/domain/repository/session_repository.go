@@ -11,14 +11,26 @@ import (
 type SessionRepository interface {
 	Create(ctx context.Context, session *models.Session) (*models.Session, error)
 	GetByID(ctx context.Context, sessionID uuid.UUID) (*models.Session, error)
-	GetByRefreshToken(ctx context.Context, refreshToken string) (*models.Session, error)
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*models.Session, error)
+	GetByRotatedRefreshToken(ctx context.Context, refreshTokenHash string) (*models.Session, error)
+	AddRotatedRefreshToken(ctx context.Context, sessionID uuid.UUID, refreshTokenHash string) error
 	Update(ctx context.Context, session *models.Session) (*models.Session, error)
+	// Rotate updates the session only if it is not revoked and its refresh
+	// token is still the one with the given hash, otherwise it returns
+	// ErrStaleSession.
+	Rotate(ctx context.Context, session *models.Session, refreshTokenHash string) (*models.Session, error)
 	Delete(ctx context.Context, sessionID uuid.UUID) (*time.Time, error)
-	RevokeByUserID(ctx context.Context, userID uuid.UUID) error
+	// Purge hard deletes up to limit sessions that expired, or were revoked
+	// or deleted, before the given time.
+	Purge(ctx context.Context, before time.Time, limit int) (int64, error)
+	Revoke(ctx context.Context, sessionID uuid.UUID) (*models.Session, error)
+	RevokeByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	RevokeOldestByUserID(ctx context.Context, userID uuid.UUID, keep int) ([]models.Session, error)
 }
 
 type SessionCache interface {
-	Get(ctx context.Context, key string) (models.Session, error)
-	Set(ctx context.Context, key string, value models.Session, ttl time.Duration) error
+	Get(ctx context.Context, key string) (CacheEntry[models.Session], error)
+	Set(ctx context.Context, key string, value CacheEntry[models.Session], ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// TokenDenylist holds the IDs of revoked access tokens until they expire.
+type TokenDenylist interface {
+	Add(ctx context.Context, tokenID string, ttl time.Duration) error
+	Contains(ctx context.Context, tokenID string) (bool, error)
+}
+
+// SessionDenylist holds the IDs of revoked sessions until the access tokens
+// issued for them expire.
+type SessionDenylist interface {
+	Add(ctx context.Context, sessionID string, ttl time.Duration) error
+	Contains(ctx context.Context, sessionID string) (bool, error)
+}
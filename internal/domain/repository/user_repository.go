@@ -18,7 +18,7 @@ type UserRepository interface {
 }
 
 type UserCache interface {
-	Get(ctx context.Context, key string) (models.User, error)
-	Set(ctx context.Context, key string, value models.User, ttl time.Duration) error
+	Get(ctx context.Context, key string) (CacheEntry[models.User], error)
+	Set(ctx context.Context, key string, value CacheEntry[models.User], ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
 }
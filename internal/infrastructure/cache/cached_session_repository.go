@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CachedSessionRepository is a cache-aside decorator of a SessionRepository.
+// Sessions are cached by refresh token hash, so a rotation moves the session
+// to a new key and the old one is evicted. A stale entry can not be rotated,
+// Rotate is checked against the database.
+type CachedSessionRepository struct {
+	repository.SessionRepository
+	cache *readThrough[models.Session]
+}
+
+const sessionCacheName = "session"
+
+func NewCachedSessionRepository(
+	sessionRepository repository.SessionRepository,
+	sessionCache repository.SessionCache,
+	cfg Config,
+	txs Transactions,
+	meter metric.Meter,
+	log *slog.Logger,
+) (*CachedSessionRepository, error) {
+	cache, err := newReadThrough[models.Session](sessionCacheName, cfg, sessionCache, nil, txs, nil, meter, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachedSessionRepository{
+		SessionRepository: sessionRepository,
+		cache:             cache,
+	}, nil
+}
+
+func (r *CachedSessionRepository) GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*models.Session, error) {
+	return r.cache.get(ctx, refreshTokenHash, func(ctx context.Context) (*models.Session, error) {
+		return r.SessionRepository.GetByRefreshTokenHash(ctx, refreshTokenHash)
+	})
+}
+
+func (r *CachedSessionRepository) Update(ctx context.Context, session *models.Session) (*models.Session, error) {
+	r.evict(ctx, session.ID)
+
+	updatedSession, err := r.SessionRepository.Update(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.invalidate(ctx, updatedSession.RefreshToken.Hash)
+
+	return updatedSession, nil
+}
+
+func (r *CachedSessionRepository) Rotate(ctx context.Context, session *models.Session, refreshTokenHash string) (*models.Session, error) {
+	// evicted even if the rotation fails, the entry is stale in that case
+	r.cache.invalidate(ctx, refreshTokenHash)
+
+	return r.SessionRepository.Rotate(ctx, session, refreshTokenHash)
+}
+
+func (r *CachedSessionRepository) Delete(ctx context.Context, sessionID uuid.UUID) (*time.Time, error) {
+	r.evict(ctx, sessionID)
+
+	return r.SessionRepository.Delete(ctx, sessionID)
+}
+
+func (r *CachedSessionRepository) Revoke(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
+	revokedSession, err := r.SessionRepository.Revoke(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.invalidate(ctx, revokedSession.RefreshToken.Hash)
+
+	return revokedSession, nil
+}
+
+func (r *CachedSessionRepository) RevokeByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	revokedSessions, err := r.SessionRepository.RevokeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.invalidate(ctx, refreshTokenHashes(revokedSessions)...)
+
+	return revokedSessions, nil
+}
+
+func (r *CachedSessionRepository) RevokeOldestByUserID(ctx context.Context, userID uuid.UUID, keep int) ([]models.Session, error) {
+	revokedSessions, err := r.SessionRepository.RevokeOldestByUserID(ctx, userID, keep)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.invalidate(ctx, refreshTokenHashes(revokedSessions)...)
+
+	return revokedSessions, nil
+}
+
+// evict removes the key of the session as currently stored.
+func (r *CachedSessionRepository) evict(ctx context.Context, sessionID uuid.UUID) {
+	if session, err := r.SessionRepository.GetByID(ctx, sessionID); err == nil {
+		r.cache.invalidate(ctx, session.RefreshToken.Hash)
+	}
+}
+
+func refreshTokenHashes(sessions []models.Session) []string {
+	hashes := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		hashes = append(hashes, session.RefreshToken.Hash)
+	}
+
+	return hashes
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CachedUserRepository is a cache-aside decorator of a UserRepository. Users
+// are cached by ID and by username, mutations evict both keys and broadcast
+// the eviction to the other replicas. Unknown usernames are cached as well,
+// so that guessing them does not reach the database.
+type CachedUserRepository struct {
+	repository.UserRepository
+	cache *readThrough[models.User]
+}
+
+const userCacheName = "user"
+
+func NewCachedUserRepository(
+	userRepository repository.UserRepository,
+	userCache repository.UserCache,
+	cfg Config,
+	txs Transactions,
+	bus *InvalidationBus,
+	meter metric.Meter,
+	log *slog.Logger,
+) (*CachedUserRepository, error) {
+	cache, err := newReadThrough[models.User](userCacheName, cfg, userCache, repository.ErrUserNotFound, txs, bus, meter, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachedUserRepository{
+		UserRepository: userRepository,
+		cache:          cache,
+	}, nil
+}
+
+func (r *CachedUserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	createdUser, err := r.UserRepository.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// the username may be cached as unknown
+	r.cache.invalidate(ctx, usernameKey(createdUser.Username))
+
+	return createdUser, nil
+}
+
+func (r *CachedUserRepository) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	return r.cache.get(ctx, userIDKey(userID), func(ctx context.Context) (*models.User, error) {
+		return r.UserRepository.GetByID(ctx, userID)
+	})
+}
+
+func (r *CachedUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.cache.get(ctx, usernameKey(username), func(ctx context.Context) (*models.User, error) {
+		return r.UserRepository.GetByUsername(ctx, username)
+	})
+}
+
+func (r *CachedUserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	keys := r.storedKeys(ctx, user.ID)
+
+	updatedUser, err := r.UserRepository.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// a read between the eviction and the write would cache the old user
+	// again, so the keys are only evicted once the write is done
+	r.cache.invalidate(ctx, append(keys, usernameKey(updatedUser.Username))...)
+
+	return updatedUser, nil
+}
+
+func (r *CachedUserRepository) Delete(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	keys := r.storedKeys(ctx, userID)
+
+	deletedAt, err := r.UserRepository.Delete(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.invalidate(ctx, keys...)
+
+	return deletedAt, nil
+}
+
+// storedKeys returns the keys of the user as currently stored, so that a
+// renamed user does not stay reachable by the old username.
+func (r *CachedUserRepository) storedKeys(ctx context.Context, userID uuid.UUID) []string {
+	keys := []string{userIDKey(userID)}
+
+	if user, err := r.UserRepository.GetByID(ctx, userID); err == nil {
+		keys = append(keys, usernameKey(user.Username))
+	}
+
+	return keys
+}
+
+func userIDKey(userID uuid.UUID) string {
+	return "id:" + userID.String()
+}
+
+func usernameKey(username string) string {
+	return "username:" + username
+}
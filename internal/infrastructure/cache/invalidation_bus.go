@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
+)
+
+const invalidationChannel = "cache:invalidations"
+
+// Evicter is an in-process cache tier that has to drop keys invalidated on
+// any replica.
+type Evicter interface {
+	Evict(keys ...string)
+}
+
+type invalidationMessage struct {
+	Cache string   `json:"cache"`
+	Keys  []string `json:"keys"`
+}
+
+// InvalidationBus broadcasts cache invalidations to all replicas over Redis
+// pub/sub and evicts the keys from the tiers registered for the cache.
+// Messages published while a replica is disconnected are lost, so local tiers
+// must still expire their entries.
+type InvalidationBus struct {
+	redis.Database
+	mu       sync.RWMutex
+	evicters map[string][]Evicter
+	log      *slog.Logger
+}
+
+func NewInvalidationBus(db redis.Database, log *slog.Logger) *InvalidationBus {
+	return &InvalidationBus{
+		Database: db,
+		evicters: make(map[string][]Evicter),
+		log:      log,
+	}
+}
+
+// Register subscribes the tier to invalidations of the named cache.
+func (b *InvalidationBus) Register(cache string, evicter Evicter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.evicters[cache] = append(b.evicters[cache], evicter)
+}
+
+func (b *InvalidationBus) Publish(ctx context.Context, cache string, keys ...string) error {
+	bytes, err := json.Marshal(invalidationMessage{
+		Cache: cache,
+		Keys:  keys,
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.Client.Publish(ctx, invalidationChannel, bytes).Err()
+}
+
+func (b *InvalidationBus) Run(ctx context.Context) {
+	pubsub := b.Client.Subscribe(ctx, invalidationChannel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var message invalidationMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				b.log.Warn("decode cache invalidation", slog.String("error", err.Error()))
+				continue
+			}
+
+			b.evict(message)
+		}
+	}
+}
+
+func (b *InvalidationBus) evict(message invalidationMessage) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, evicter := range b.evicters[message.Cache] {
+		evicter.Evict(message.Keys...)
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// lru is a size-bounded map that evicts the least recently used entry when
+// full and drops entries once they expire.
+type lru[V any] struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newLRU[V any](size int) *lru[V] {
+	return &lru[V]{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+func (c *lru[V]) get(key string, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[V])
+	if !now.Before(entry.expiresAt) {
+		c.remove(element)
+
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+func (c *lru[V]) set(key string, value V, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *lru[V]) delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+func (c *lru[V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry[V]).key)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	redisdb "github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
+)
+
+// RateLimiter allows up to limit requests per key in fixed time windows.
+type RateLimiter struct {
+	redisdb.Database
+	limit  int64
+	window time.Duration
+}
+
+func NewRateLimiter(db redisdb.Database, limit int64, window time.Duration) (*RateLimiter, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+
+	if window <= 0 {
+		return nil, fmt.Errorf("invalid window %s", window)
+	}
+
+	return &RateLimiter{
+		Database: db,
+		limit:    limit,
+		window:   window,
+	}, nil
+}
+
+func (r *RateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	key = createRateLimitKey(key, time.Now().UnixNano()/int64(r.window))
+
+	var count *redis.IntCmd
+
+	if _, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, r.window)
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return count.Val() <= r.limit, nil
+}
+
+func createRateLimitKey(key string, window int64) string {
+	return fmt.Sprintf("ratelimit:%s:%d", key, window)
+}
@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+type Config struct {
+	TTL time.Duration
+	// NegativeTTL is how long a lookup that found nothing is cached, 0
+	// disables negative caching.
+	NegativeTTL time.Duration
+	// Beta scales the probabilistic early refresh (XFetch): the larger it is,
+	// the earlier an entry may be reloaded before it expires. 0 disables it.
+	Beta float64
+}
+
+type store[T any] interface {
+	Get(ctx context.Context, key string) (repository.CacheEntry[T], error)
+	Set(ctx context.Context, key string, value repository.CacheEntry[T], ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Transactions tells whether the context carries a transaction and defers work
+// until that transaction commits.
+type Transactions interface {
+	InTransaction(ctx context.Context) bool
+	AfterCommit(ctx context.Context, f func(ctx context.Context))
+}
+
+// readThrough serves values from a store and loads them from the source of
+// truth on a miss. Cache errors are never returned, the store is best effort.
+type readThrough[T any] struct {
+	name     string
+	cfg      Config
+	store    store[T]
+	notFound error
+	txs      Transactions
+	bus      *InvalidationBus
+	loads    singleflight.Group
+	hits     metric.Int64Counter
+	misses   metric.Int64Counter
+	attrs    metric.MeasurementOption
+	log      *slog.Logger
+}
+
+// newReadThrough creates a read-through cache; loads failing with notFound
+// are cached as missing entries when negative caching is enabled.
+func newReadThrough[T any](
+	name string,
+	cfg Config,
+	s store[T],
+	notFound error,
+	txs Transactions,
+	bus *InvalidationBus,
+	meter metric.Meter,
+	log *slog.Logger,
+) (*readThrough[T], error) {
+	hits, err := meter.Int64Counter(
+		"auth_cache_hits",
+		metric.WithDescription("Number of lookups served from the cache"),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	misses, err := meter.Int64Counter(
+		"auth_cache_misses",
+		metric.WithDescription("Number of lookups that fell through to the database"),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &readThrough[T]{
+		name:     name,
+		cfg:      cfg,
+		store:    s,
+		notFound: notFound,
+		txs:      txs,
+		bus:      bus,
+		hits:     hits,
+		misses:   misses,
+		attrs:    metric.WithAttributes(attribute.String("cache", name)),
+		log:      log.With(slog.String("cache", name)),
+	}, nil
+}
+
+func (c *readThrough[T]) get(ctx context.Context, key string, load func(ctx context.Context) (*T, error)) (*T, error) {
+	// a read within a transaction has to see the writes of the transaction,
+	// and what it reads may still be rolled back
+	if c.txs.InTransaction(ctx) {
+		return load(ctx)
+	}
+
+	if entry, err := c.store.Get(ctx, key); err == nil && !c.refreshEarly(entry, time.Now()) {
+		c.hits.Add(ctx, 1, c.attrs)
+
+		if entry.Missing {
+			return nil, c.notFound
+		}
+
+		return &entry.Value, nil
+	}
+
+	c.misses.Add(ctx, 1, c.attrs)
+
+	// concurrent misses of a key share one load, which is detached from the
+	// cancellation of the caller that happened to start it
+	result := c.loads.DoChan(key, func() (any, error) {
+		return c.load(context.WithoutCancel(ctx), key, load)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		value := *res.Val.(*T)
+
+		return &value, nil
+	}
+}
+
+func (c *readThrough[T]) load(ctx context.Context, key string, load func(ctx context.Context) (*T, error)) (*T, error) {
+	start := time.Now()
+
+	value, err := load(ctx)
+
+	delta := time.Since(start)
+
+	switch {
+	case err == nil:
+		c.set(ctx, key, repository.CacheEntry[T]{Value: *value, Delta: delta}, c.cfg.TTL)
+	case c.cfg.NegativeTTL > 0 && errors.Is(err, c.notFound):
+		c.set(ctx, key, repository.CacheEntry[T]{Missing: true, Delta: delta}, c.cfg.NegativeTTL)
+	}
+
+	return value, err
+}
+
+func (c *readThrough[T]) set(ctx context.Context, key string, entry repository.CacheEntry[T], ttl time.Duration) {
+	entry.ExpiresAt = time.Now().Add(ttl)
+
+	if err := c.store.Set(ctx, key, entry, ttl); err != nil {
+		c.log.Warn("set value to cache", slog.String("error", err.Error()))
+	}
+}
+
+// refreshEarly decides whether to reload an entry before it expires, so that
+// a popular key is refreshed by a single caller instead of expiring for all
+// of them at once. The chance grows as the expiry nears and with the time the
+// value took to load.
+func (c *readThrough[T]) refreshEarly(entry repository.CacheEntry[T], now time.Time) bool {
+	if c.cfg.Beta <= 0 {
+		return false
+	}
+
+	gap := float64(entry.Delta) * c.cfg.Beta * -math.Log(1-rand.Float64())
+
+	return !now.Add(time.Duration(gap)).Before(entry.ExpiresAt)
+}
+
+// invalidate evicts the keys right away and once more after the transaction
+// of ctx commits, as a concurrent read may have cached the old value in the
+// meantime. Other replicas are notified after the commit.
+func (c *readThrough[T]) invalidate(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	c.delete(ctx, keys)
+
+	c.txs.AfterCommit(ctx, func(ctx context.Context) {
+		c.delete(ctx, keys)
+
+		if c.bus == nil {
+			return
+		}
+
+		if err := c.bus.Publish(ctx, c.name, keys...); err != nil {
+			c.log.Warn("publish cache invalidation", slog.String("error", err.Error()))
+		}
+	})
+}
+
+func (c *readThrough[T]) delete(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := c.store.Delete(ctx, key); err != nil {
+			c.log.Warn("delete value from cache", slog.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
+)
+
+type SessionDenylist struct {
+	redis.Database
+}
+
+func NewSessionDenylist(db redis.Database) *SessionDenylist {
+	return &SessionDenylist{
+		Database: db,
+	}
+}
+
+func (r *SessionDenylist) Add(ctx context.Context, sessionID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	key := createSessionDenylistKey(sessionID)
+
+	return r.Client.Set(ctx, key, 1, ttl).Err()
+}
+
+func (r *SessionDenylist) Contains(ctx context.Context, sessionID string) (bool, error) {
+	key := createSessionDenylistKey(sessionID)
+
+	n, err := r.Client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func createSessionDenylistKey(sessionID string) string {
+	return fmt.Sprintf("denylist:session:%s", sessionID)
+}
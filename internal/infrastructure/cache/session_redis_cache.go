@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
 	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
 )
 
@@ -20,26 +21,26 @@ func NewSessionCache(db redis.Database) *SessionCache {
 	}
 }
 
-func (r *SessionCache) Get(ctx context.Context, id string) (models.Session, error) {
+func (r *SessionCache) Get(ctx context.Context, id string) (repository.CacheEntry[models.Session], error) {
 	key := createSessionKey(id)
 
 	bytes, err := r.Client.Get(ctx, key).Bytes()
 	if err != nil {
-		return models.Session{}, err
+		return repository.CacheEntry[models.Session]{}, err
 	}
 
-	var session models.Session
-	if err := json.Unmarshal(bytes, &session); err != nil {
-		return models.Session{}, err
+	var entry repository.CacheEntry[models.Session]
+	if err := json.Unmarshal(bytes, &entry); err != nil {
+		return repository.CacheEntry[models.Session]{}, err
 	}
 
-	return session, nil
+	return entry, nil
 }
 
-func (r *SessionCache) Set(ctx context.Context, id string, session models.Session, ttl time.Duration) error {
+func (r *SessionCache) Set(ctx context.Context, id string, entry repository.CacheEntry[models.Session], ttl time.Duration) error {
 	key := createSessionKey(id)
 
-	bytes, err := json.Marshal(session)
+	bytes, err := json.Marshal(entry)
 	if err != nil {
 		return err
 	}
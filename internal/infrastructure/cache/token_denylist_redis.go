@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
+)
+
+type TokenDenylist struct {
+	redis.Database
+}
+
+func NewTokenDenylist(db redis.Database) *TokenDenylist {
+	return &TokenDenylist{
+		Database: db,
+	}
+}
+
+func (r *TokenDenylist) Add(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	key := createDenylistKey(tokenID)
+
+	return r.Client.Set(ctx, key, 1, ttl).Err()
+}
+
+func (r *TokenDenylist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	key := createDenylistKey(tokenID)
+
+	n, err := r.Client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func createDenylistKey(tokenID string) string {
+	return fmt.Sprintf("denylist:%s", tokenID)
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+)
+
+type LocalCacheConfig struct {
+	// Size is the maximum number of entries kept in memory.
+	Size int
+	// TTL bounds how long an entry is served from memory, which also bounds
+	// staleness when an invalidation message is lost.
+	TTL time.Duration
+}
+
+// LocalUserCache is an in-process tier in front of another UserCache. Writes
+// and deletes go through to the next tier; keys invalidated on other replicas
+// are evicted through the InvalidationBus.
+type LocalUserCache struct {
+	cfg     LocalCacheConfig
+	next    repository.UserCache
+	entries *lru[repository.CacheEntry[models.User]]
+}
+
+func NewLocalUserCache(cfg LocalCacheConfig, next repository.UserCache, bus *InvalidationBus) *LocalUserCache {
+	c := &LocalUserCache{
+		cfg:     cfg,
+		next:    next,
+		entries: newLRU[repository.CacheEntry[models.User]](cfg.Size),
+	}
+
+	bus.Register(userCacheName, c)
+
+	return c
+}
+
+func (c *LocalUserCache) Get(ctx context.Context, id string) (repository.CacheEntry[models.User], error) {
+	now := time.Now()
+
+	if entry, ok := c.entries.get(id, now); ok {
+		return entry, nil
+	}
+
+	entry, err := c.next.Get(ctx, id)
+	if err != nil {
+		return repository.CacheEntry[models.User]{}, err
+	}
+
+	c.entries.set(id, entry, c.expiresAt(entry, now))
+
+	return entry, nil
+}
+
+func (c *LocalUserCache) Set(ctx context.Context, id string, entry repository.CacheEntry[models.User], ttl time.Duration) error {
+	c.entries.set(id, entry, c.expiresAt(entry, time.Now()))
+
+	return c.next.Set(ctx, id, entry, ttl)
+}
+
+func (c *LocalUserCache) Delete(ctx context.Context, id string) error {
+	c.entries.delete(id)
+
+	return c.next.Delete(ctx, id)
+}
+
+// Evict drops the keys from memory only, the next tier is shared by all
+// replicas and already invalidated by the publisher.
+func (c *LocalUserCache) Evict(keys ...string) {
+	c.entries.delete(keys...)
+}
+
+func (c *LocalUserCache) expiresAt(entry repository.CacheEntry[models.User], now time.Time) time.Time {
+	expiresAt := now.Add(c.cfg.TTL)
+	if !entry.ExpiresAt.IsZero() && entry.ExpiresAt.Before(expiresAt) {
+		return entry.ExpiresAt
+	}
+
+	return expiresAt
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
 	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
 )
 
@@ -20,26 +21,26 @@ func NewUserCache(db redis.Database) *UserCache {
 	}
 }
 
-func (r *UserCache) Get(ctx context.Context, username string) (models.User, error) {
-	key := createUserKey(username)
+func (r *UserCache) Get(ctx context.Context, id string) (repository.CacheEntry[models.User], error) {
+	key := createUserKey(id)
 
 	bytes, err := r.Client.Get(ctx, key).Bytes()
 	if err != nil {
-		return models.User{}, err
+		return repository.CacheEntry[models.User]{}, err
 	}
 
-	var user models.User
-	if err := json.Unmarshal(bytes, &user); err != nil {
-		return models.User{}, err
+	var entry repository.CacheEntry[models.User]
+	if err := json.Unmarshal(bytes, &entry); err != nil {
+		return repository.CacheEntry[models.User]{}, err
 	}
 
-	return user, nil
+	return entry, nil
 }
 
-func (r *UserCache) Set(ctx context.Context, username string, user models.User, ttl time.Duration) error {
-	key := createUserKey(username)
+func (r *UserCache) Set(ctx context.Context, id string, entry repository.CacheEntry[models.User], ttl time.Duration) error {
+	key := createUserKey(id)
 
-	bytes, err := json.Marshal(user)
+	bytes, err := json.Marshal(entry)
 	if err != nil {
 		return err
 	}
@@ -47,12 +48,12 @@ func (r *UserCache) Set(ctx context.Context, username string, user models.User,
 	return r.Client.Set(ctx, key, bytes, ttl).Err()
 }
 
-func (r *UserCache) Delete(ctx context.Context, username string) error {
-	key := createUserKey(username)
+func (r *UserCache) Delete(ctx context.Context, id string) error {
+	key := createUserKey(id)
 
 	return r.Client.Del(ctx, key).Err()
 }
 
-func createUserKey(username string) string {
-	return fmt.Sprintf("user:%s", username)
+func createUserKey(id string) string {
+	return fmt.Sprintf("user:%s", id)
 }
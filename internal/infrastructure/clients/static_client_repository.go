@@ -0,0 +1,36 @@
+package clients
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+)
+
+var ErrClientNotFound = errors.New("client not found")
+
+// StaticClientRepository serves the OAuth clients listed in the config.
+type StaticClientRepository struct {
+	clients map[string]models.Client
+}
+
+func NewStaticClientRepository(clients []models.Client) *StaticClientRepository {
+	r := &StaticClientRepository{
+		clients: make(map[string]models.Client, len(clients)),
+	}
+
+	for _, client := range clients {
+		r.clients[client.ID] = client
+	}
+
+	return r
+}
+
+func (r *StaticClientRepository) GetByID(_ context.Context, clientID string) (*models.Client, error) {
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, ErrClientNotFound
+	}
+
+	return &client, nil
+}
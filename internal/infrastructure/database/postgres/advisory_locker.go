@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"context"
+)
+
+const advisoryLockQueryTryLock = `SELECT pg_try_advisory_xact_lock($1)`
+
+type AdvisoryLocker struct {
+	txManager *TransactionManager
+}
+
+func NewAdvisoryLocker(txManager *TransactionManager) *AdvisoryLocker {
+	return &AdvisoryLocker{
+		txManager: txManager,
+	}
+}
+
+func (l *AdvisoryLocker) TryLock(ctx context.Context, key int64) (bool, error) {
+	db := l.txManager.TxOrDB(ctx)
+
+	var locked bool
+	if err := db.QueryRow(ctx, advisoryLockQueryTryLock, key).Scan(&locked); err != nil {
+		return false, err
+	}
+
+	return locked, nil
+}
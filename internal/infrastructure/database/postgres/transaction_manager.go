@@ -12,7 +12,10 @@ import (
 
 type txKeyType string
 
-var txKeyValue = txKeyType("tx")
+var (
+	txKeyValue      = txKeyType("tx")
+	txHooksKeyValue = txKeyType("tx_hooks")
+)
 
 type Transaction interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
@@ -48,7 +51,10 @@ func (m *TransactionManager) WithTransaction(ctx context.Context, f func(ctx con
 		return err
 	}
 
+	var hooks []func(ctx context.Context)
+
 	ctxWithTx := context.WithValue(ctx, txKeyValue, tx)
+	ctxWithTx = context.WithValue(ctxWithTx, txHooksKeyValue, &hooks)
 	if err := f(ctxWithTx); err != nil {
 		if errRollback := tx.Rollback(ctx); errRollback != nil {
 			return errors.Join(err, errRollback)
@@ -60,9 +66,32 @@ func (m *TransactionManager) WithTransaction(ctx context.Context, f func(ctx con
 		return err
 	}
 
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+
 	return nil
 }
 
+// AfterCommit defers f until the transaction of ctx is committed, f is dropped
+// on rollback. Without a transaction f runs right away.
+func (m *TransactionManager) AfterCommit(ctx context.Context, f func(ctx context.Context)) {
+	hooks, ok := ctx.Value(txHooksKeyValue).(*[]func(ctx context.Context))
+	if !ok {
+		f(ctx)
+		return
+	}
+
+	*hooks = append(*hooks, f)
+}
+
+// InTransaction reports whether ctx carries a transaction.
+func (m *TransactionManager) InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKeyValue).(Transaction)
+
+	return ok
+}
+
 func (m *TransactionManager) TxOrDB(ctx context.Context) Transaction {
 	tx, ok := ctx.Value(txKeyValue).(Transaction)
 	if !ok {
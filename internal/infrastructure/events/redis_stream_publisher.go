@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	redisdb "github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/redis"
+)
+
+type RedisStreamConfig struct {
+	Stream string
+	// MaxLen caps the stream length approximately, 0 means no cap.
+	MaxLen int64
+}
+
+// RedisStreamPublisher appends outbox events to a Redis stream. The event ID
+// goes along, so that consumers can drop the duplicates an at-least-once
+// relay produces.
+type RedisStreamPublisher struct {
+	redisdb.Database
+	cfg RedisStreamConfig
+}
+
+func NewRedisStreamPublisher(db redisdb.Database, cfg RedisStreamConfig) *RedisStreamPublisher {
+	return &RedisStreamPublisher{
+		Database: db,
+		cfg:      cfg,
+	}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return p.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.cfg.Stream,
+		MaxLen: p.cfg.MaxLen,
+		Approx: true,
+		Values: map[string]any{
+			"id":          event.ID.String(),
+			"type":        event.Type,
+			"payload":     string(event.Payload),
+			"occurred_at": event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+)
+
+// SecurityEventLogger writes security events to a dedicated structured log
+// stream, where they are picked up by alerting.
+type SecurityEventLogger struct {
+	log *slog.Logger
+}
+
+func NewSecurityEventLogger(log *slog.Logger) *SecurityEventLogger {
+	return &SecurityEventLogger{
+		log: log.With(slog.String("stream", "security")),
+	}
+}
+
+func (l *SecurityEventLogger) Emit(ctx context.Context, event models.SecurityEvent) {
+	l.log.WarnContext(ctx, "security event",
+		slog.String("type", event.Type),
+		slog.String("user_id", event.UserID.String()),
+		slog.String("session_id", event.SessionID.String()),
+		slog.String("occurred_at", event.OccurredAt.Format(time.RFC3339)),
+	)
+}
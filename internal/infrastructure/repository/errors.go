@@ -0,0 +1,16 @@
+package pgrepo
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgUniqueViolation is the SQLSTATE of a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,47 @@
+package pgrepo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+)
+
+type OutboxEventEntity struct {
+	ID         uuid.UUID `db:"id"`
+	Type       string    `db:"type"`
+	Payload    []byte    `db:"payload"`
+	OccurredAt time.Time `db:"occurred_at"`
+}
+
+// ReferralRedeemedPayload is the published encoding of the event, consumers
+// depend on its field names.
+type ReferralRedeemedPayload struct {
+	ReferrerID uuid.UUID `json:"referrer_id"`
+	RefereeID  uuid.UUID `json:"referee_id"`
+	Code       string    `json:"code"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+func referralRedeemedFromModel(event models.ReferralRedeemed) ReferralRedeemedPayload {
+	return ReferralRedeemedPayload{
+		ReferrerID: event.ReferrerID,
+		RefereeID:  event.RefereeID,
+		Code:       event.Code,
+		RedeemedAt: event.RedeemedAt.UTC(),
+	}
+}
+
+func outboxEventsToModel(outboxEventEntityList []OutboxEventEntity) []models.OutboxEvent {
+	outboxEventList := make([]models.OutboxEvent, 0, len(outboxEventEntityList))
+	for _, outboxEventEntity := range outboxEventEntityList {
+		outboxEventList = append(outboxEventList, models.OutboxEvent{
+			ID:         outboxEventEntity.ID,
+			Type:       outboxEventEntity.Type,
+			Payload:    outboxEventEntity.Payload,
+			OccurredAt: outboxEventEntity.OccurredAt,
+		})
+	}
+
+	return outboxEventList
+}
@@ -0,0 +1,33 @@
+package pgrepo
+
+const outboxQueryAdd = `
+	INSERT INTO outbox (
+		type,
+		payload,
+		occurred_at
+	) VALUES (
+		$1, $2, $3
+	)
+`
+
+const outboxQueryListPending = `
+	SELECT     
+		id,
+		type,
+		payload,
+		occurred_at
+	FROM 
+		outbox
+	ORDER BY
+		occurred_at,
+		id
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+`
+
+const outboxQueryDelete = `
+	DELETE FROM 
+		outbox
+	WHERE 
+		id = ANY($1)
+`
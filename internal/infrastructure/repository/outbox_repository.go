@@ -0,0 +1,82 @@
+package pgrepo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/postgres"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OutboxRepository struct {
+	db        postgres.Database
+	txManager postgres.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewOutboxRepository(db postgres.Database, txManager postgres.TransactionManager, log *slog.Logger, tracer trace.Tracer) *OutboxRepository {
+	return &OutboxRepository{
+		db:        db,
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *OutboxRepository) AddReferralRedeemed(ctx context.Context, event models.ReferralRedeemed) error {
+	ctx, span := s.tracer.Start(ctx, "OutboxRepository.AddReferralRedeemed")
+	defer span.End()
+
+	payload, err := json.Marshal(referralRedeemedFromModel(event))
+	if err != nil {
+		return err
+	}
+
+	db := s.txManager.TxOrDB(ctx)
+
+	_, err = db.Exec(ctx, outboxQueryAdd, models.DomainEventReferralRedeemed, payload, event.RedeemedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *OutboxRepository) ListPending(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	ctx, span := s.tracer.Start(ctx, "OutboxRepository.ListPending")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, outboxQueryListPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outboxEventEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[OutboxEventEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxEventsToModel(outboxEventEntityList), nil
+}
+
+func (s *OutboxRepository) Delete(ctx context.Context, eventIDs []uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "OutboxRepository.Delete")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	_, err := db.Exec(ctx, outboxQueryDelete, eventIDs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -8,23 +8,81 @@ import (
 )
 
 type ReferralCodeEntity struct {
-	ID        uuid.UUID `db:"id"`
-	UserID    uuid.UUID `db:"user_id"`
-	ExpiredAt time.Time `db:"expired_at"`
+	ID             uuid.UUID `db:"id"`
+	UserID         uuid.UUID `db:"user_id"`
+	Code           string    `db:"code"`
+	Campaign       *string   `db:"campaign"`
+	MaxRedemptions *int      `db:"max_redemptions"`
+	Redemptions    int       `db:"redemptions"`
+	CreatedAt      time.Time `db:"created_at"`
+	ExpiredAt      time.Time `db:"expired_at"`
+}
+
+type ReferralRedemptionEntity struct {
+	ID             uuid.UUID `db:"id"`
+	ReferralCodeID uuid.UUID `db:"referral_code_id"`
+	ReferrerID     uuid.UUID `db:"referrer_id"`
+	RefereeID      uuid.UUID `db:"referee_id"`
+	Code           string    `db:"code"`
+	Campaign       *string   `db:"campaign"`
+	RedeemedAt     time.Time `db:"redeemed_at"`
 }
 
 func referralCodeToModel(referralCode *ReferralCodeEntity) *models.ReferralCode {
-	return &models.ReferralCode{
-		ID:        referralCode.ID,
-		UserID:    referralCode.UserID,
-		ExpiredAt: referralCode.ExpiredAt,
+	model := &models.ReferralCode{
+		ID:          referralCode.ID,
+		UserID:      referralCode.UserID,
+		Code:        referralCode.Code,
+		Redemptions: referralCode.Redemptions,
+		CreatedAt:   referralCode.CreatedAt,
+		ExpiredAt:   referralCode.ExpiredAt,
 	}
+
+	if referralCode.Campaign != nil {
+		model.Campaign = *referralCode.Campaign
+	}
+
+	if referralCode.MaxRedemptions != nil {
+		model.MaxRedemptions = *referralCode.MaxRedemptions
+	}
+
+	return model
 }
 
 func referralCodeFromModel(referralCode *models.ReferralCode) *ReferralCodeEntity {
-	return &ReferralCodeEntity{
-		ID:        referralCode.ID,
-		UserID:    referralCode.UserID,
-		ExpiredAt: referralCode.ExpiredAt,
+	entity := &ReferralCodeEntity{
+		ID:          referralCode.ID,
+		UserID:      referralCode.UserID,
+		Code:        referralCode.Code,
+		Redemptions: referralCode.Redemptions,
+		CreatedAt:   referralCode.CreatedAt,
+		ExpiredAt:   referralCode.ExpiredAt,
+	}
+
+	if referralCode.Campaign != "" {
+		entity.Campaign = &referralCode.Campaign
 	}
+
+	if referralCode.MaxRedemptions > 0 {
+		entity.MaxRedemptions = &referralCode.MaxRedemptions
+	}
+
+	return entity
+}
+
+func referralRedemptionToModel(redemption *ReferralRedemptionEntity) *models.ReferralRedemption {
+	model := &models.ReferralRedemption{
+		ID:             redemption.ID,
+		ReferralCodeID: redemption.ReferralCodeID,
+		ReferrerID:     redemption.ReferrerID,
+		RefereeID:      redemption.RefereeID,
+		Code:           redemption.Code,
+		RedeemedAt:     redemption.RedeemedAt,
+	}
+
+	if redemption.Campaign != nil {
+		model.Campaign = *redemption.Campaign
+	}
+
+	return model
 }
@@ -1,19 +1,32 @@
 package pgrepo
 
+// the unique index on code is partial, so it has to be named as the conflict
+// target along with its predicate
 const referralCodeQueryCreate = `
-	INSERT INTO referralCode (
+	INSERT INTO referral_code (
 		user_id,
+		code,
+		campaign,
+		max_redemptions,
+		expired_at
 	) VALUES (
-		$1, $2
+		$1, $2, $3, $4, $5
 	)
+	ON CONFLICT (code) WHERE deleted_at IS NULL DO NOTHING
 	RETURNING 
 		id,
-		user_id
+		user_id,
+		code,
+		campaign,
+		max_redemptions,
+		redemptions,
+		created_at,
+		expired_at
 `
 
 const referralCodeQueryDelete = `
 	UPDATE 
-		referralCode
+		referral_code
 	SET 
 		deleted_at = COALESCE(deleted_at, NOW())
 	WHERE 
@@ -22,12 +35,151 @@ const referralCodeQueryDelete = `
 		deleted_at;
 `
 
+const referralCodeQueryDeleteByUserID = `
+	UPDATE 
+		referral_code
+	SET 
+		deleted_at = NOW()
+	WHERE 
+		user_id = $1 AND
+		deleted_at IS NULL
+`
+
+const referralCodeQueryGetByID = `
+	SELECT     
+		id, 
+		user_id,
+		code,
+		campaign,
+		max_redemptions,
+		redemptions,
+		created_at,
+		expired_at
+	FROM 
+		referral_code
+	WHERE 
+		id = $1 AND
+		expired_at > NOW() AND
+		deleted_at IS NULL
+`
+
+const referralCodeQueryGetByCode = `
+	SELECT     
+		id, 
+		user_id,
+		code,
+		campaign,
+		max_redemptions,
+		redemptions,
+		created_at,
+		expired_at
+	FROM 
+		referral_code
+	WHERE 
+		code = $1 AND
+		expired_at > NOW() AND
+		deleted_at IS NULL
+`
+
+const referralCodeQueryGetByUserID = `
+	SELECT     
+		id, 
+		user_id,
+		code,
+		campaign,
+		max_redemptions,
+		redemptions,
+		created_at,
+		expired_at
+	FROM 
+		referral_code
+	WHERE 
+		user_id = $1 AND
+		expired_at > NOW() AND
+		deleted_at IS NULL
+`
+
 const referralCodeQueryGetByUsername = `
 	SELECT     
 		refcode.id, 
-		refcode.user_id
+		refcode.user_id,
+		refcode.code,
+		refcode.campaign,
+		refcode.max_redemptions,
+		refcode.redemptions,
+		refcode.created_at,
+		refcode.expired_at
 	FROM 
-		users JOIN referral_code refcode ON users.username = $1 AND users.id = refcode.id
+		users JOIN referral_code refcode ON users.id = refcode.user_id
 	WHERE 
-		deleted_at IS NULL;
+		users.username = $1 AND
+		users.referral_discoverable = TRUE AND
+		users.deleted_at IS NULL AND
+		refcode.expired_at > NOW() AND
+		refcode.deleted_at IS NULL
+`
+
+const referralCodeQueryGetByVerifiedEmail = `
+	SELECT     
+		refcode.id, 
+		refcode.user_id,
+		refcode.code,
+		refcode.campaign,
+		refcode.max_redemptions,
+		refcode.redemptions,
+		refcode.created_at,
+		refcode.expired_at
+	FROM 
+		users JOIN referral_code refcode ON users.id = refcode.user_id
+	WHERE 
+		lower(users.email) = lower($1) AND
+		users.email_verified = TRUE AND
+		users.referral_discoverable = TRUE AND
+		users.deleted_at IS NULL AND
+		refcode.expired_at > NOW() AND
+		refcode.deleted_at IS NULL
+`
+
+// the counter is bumped conditionally, so concurrent registrations cannot
+// redeem a code more often than it allows
+const referralCodeQueryRedeem = `
+	WITH refcode AS (
+		UPDATE 
+			referral_code
+		SET 
+			redemptions = redemptions + 1
+		WHERE 
+			id = $1 AND
+			(max_redemptions IS NULL OR redemptions < max_redemptions) AND
+			expired_at > NOW() AND
+			deleted_at IS NULL
+		RETURNING 
+			id,
+			user_id,
+			code,
+			campaign
+	)
+	INSERT INTO referral_redemption (
+		referral_code_id,
+		referrer_id,
+		referee_id,
+		code,
+		campaign
+	)
+	SELECT
+		id,
+		user_id,
+		$2::uuid,
+		code,
+		campaign
+	FROM
+		refcode
+	RETURNING 
+		id,
+		referral_code_id,
+		referrer_id,
+		referee_id,
+		code,
+		campaign,
+		redeemed_at
 `
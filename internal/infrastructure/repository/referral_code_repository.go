@@ -2,12 +2,14 @@ package pgrepo
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
 	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/postgres"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -36,7 +38,15 @@ func (s *ReferralCodeRepository) Create(ctx context.Context, referralCode *model
 
 	db := s.txManager.TxOrDB(ctx)
 
-	rows, err := db.Query(ctx, referralCodeQueryCreate, referralCodeEntity.UserID)
+	args := []any{
+		referralCodeEntity.UserID,
+		referralCodeEntity.Code,
+		referralCodeEntity.Campaign,
+		referralCodeEntity.MaxRedemptions,
+		referralCodeEntity.ExpiredAt,
+	}
+
+	rows, err := db.Query(ctx, referralCodeQueryCreate, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -44,19 +54,61 @@ func (s *ReferralCodeRepository) Create(ctx context.Context, referralCode *model
 
 	createdReferralCodeEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[ReferralCodeEntity])
 	if err != nil {
+		// a taken code string is skipped by ON CONFLICT, so only the index
+		// on user_id can be violated
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrReferralCodeTaken
+		}
+
+		if isUniqueViolation(err) {
+			return nil, repository.ErrReferralCodeExists
+		}
+
 		return nil, err
 	}
 
 	return referralCodeToModel(&createdReferralCodeEntity), nil
 }
 
+func (s *ReferralCodeRepository) GetByID(ctx context.Context, referralCodeID uuid.UUID) (*models.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralCodeRepository.GetByID")
+	defer span.End()
+
+	return s.get(ctx, referralCodeQueryGetByID, referralCodeID)
+}
+
+func (s *ReferralCodeRepository) GetByCode(ctx context.Context, code string) (*models.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralCodeRepository.GetByCode")
+	defer span.End()
+
+	return s.get(ctx, referralCodeQueryGetByCode, code)
+}
+
+func (s *ReferralCodeRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralCodeRepository.GetByUserID")
+	defer span.End()
+
+	return s.get(ctx, referralCodeQueryGetByUserID, userID)
+}
+
 func (s *ReferralCodeRepository) GetByUsername(ctx context.Context, username string) (*models.ReferralCode, error) {
 	ctx, span := s.tracer.Start(ctx, "ReferralCodeRepository.GetByUsername")
 	defer span.End()
 
+	return s.get(ctx, referralCodeQueryGetByUsername, username)
+}
+
+func (s *ReferralCodeRepository) GetByVerifiedEmail(ctx context.Context, email string) (*models.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralCodeRepository.GetByVerifiedEmail")
+	defer span.End()
+
+	return s.get(ctx, referralCodeQueryGetByVerifiedEmail, email)
+}
+
+func (s *ReferralCodeRepository) get(ctx context.Context, query string, args ...any) (*models.ReferralCode, error) {
 	db := s.txManager.TxOrDB(ctx)
 
-	rows, err := db.Query(ctx, referralCodeQueryGetByUsername, username)
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +116,10 @@ func (s *ReferralCodeRepository) GetByUsername(ctx context.Context, username str
 
 	referralCodeEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[ReferralCodeEntity])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrReferralCodeNotFound
+		}
+
 		return nil, err
 	}
 
@@ -82,10 +138,52 @@ func (s *ReferralCodeRepository) Delete(ctx context.Context, referralCodeID uuid
 	}
 	defer rows.Close()
 
-	deletedAt, err := pgx.RowTo[time.Time](rows)
+	deletedAt, err := pgx.CollectOneRow(rows, pgx.RowTo[time.Time])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrReferralCodeNotFound
+		}
+
 		return nil, err
 	}
 
 	return &deletedAt, nil
 }
+
+func (s *ReferralCodeRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "ReferralCodeRepository.DeleteByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	_, err := db.Exec(ctx, referralCodeQueryDeleteByUserID, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *ReferralCodeRepository) Redeem(ctx context.Context, referralCodeID uuid.UUID, refereeID uuid.UUID) (*models.ReferralRedemption, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralCodeRepository.Redeem")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, referralCodeQueryRedeem, referralCodeID, refereeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redemptionEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[ReferralRedemptionEntity])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrReferralCodeExhausted
+		}
+
+		return nil, err
+	}
+
+	return referralRedemptionToModel(&redemptionEntity), nil
+}
@@ -0,0 +1,71 @@
+package pgrepo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+)
+
+type RefereeEntity struct {
+	UserID       uuid.UUID `db:"user_id"`
+	ReferrerID   uuid.UUID `db:"referrer_id"`
+	Username     string    `db:"username"`
+	RegisteredAt time.Time `db:"registered_at"`
+	Depth        int       `db:"depth"`
+}
+
+type ReferralLevelEntity struct {
+	Depth    int   `db:"depth"`
+	Referees int64 `db:"referees"`
+}
+
+type CampaignRedemptionsEntity struct {
+	Campaign    string `db:"campaign"`
+	Redemptions int64  `db:"redemptions"`
+	Referrers   int64  `db:"referrers"`
+}
+
+func refereesToModel(refereeEntityList []RefereeEntity) []models.Referee {
+	refereeList := make([]models.Referee, 0, len(refereeEntityList))
+	for _, refereeEntity := range refereeEntityList {
+		refereeList = append(refereeList, models.Referee{
+			UserID:       refereeEntity.UserID,
+			ReferrerID:   refereeEntity.ReferrerID,
+			Username:     refereeEntity.Username,
+			RegisteredAt: refereeEntity.RegisteredAt,
+			Depth:        refereeEntity.Depth,
+		})
+	}
+
+	return refereeList
+}
+
+// referralStatsToModel fills the levels without referees, as the query only
+// returns the levels it found.
+func referralStatsToModel(levelEntityList []ReferralLevelEntity) models.ReferralStats {
+	var stats models.ReferralStats
+
+	for _, levelEntity := range levelEntityList {
+		for len(stats.Levels) < levelEntity.Depth {
+			stats.Levels = append(stats.Levels, 0)
+		}
+
+		stats.Levels[levelEntity.Depth-1] = levelEntity.Referees
+	}
+
+	return stats
+}
+
+func campaignRedemptionsToModel(entityList []CampaignRedemptionsEntity) []models.CampaignRedemptions {
+	campaignList := make([]models.CampaignRedemptions, 0, len(entityList))
+	for _, entity := range entityList {
+		campaignList = append(campaignList, models.CampaignRedemptions{
+			Campaign:    entity.Campaign,
+			Redemptions: entity.Redemptions,
+			Referrers:   entity.Referrers,
+		})
+	}
+
+	return campaignList
+}
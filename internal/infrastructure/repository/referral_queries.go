@@ -0,0 +1,109 @@
+package pgrepo
+
+const referralQueryListReferees = `
+	SELECT     
+		id AS user_id,
+		referrer_id,
+		username,
+		created_at AS registered_at,
+		1 AS depth
+	FROM 
+		users
+	WHERE
+		referrer_id = $1 AND
+		deleted_at IS NULL
+	ORDER BY
+		created_at DESC,
+		id DESC
+	LIMIT $2
+	OFFSET $3
+`
+
+const referralQueryTree = `
+	WITH RECURSIVE tree AS (
+		SELECT
+			id,
+			referrer_id,
+			username,
+			created_at,
+			1 AS depth
+		FROM
+			users
+		WHERE
+			referrer_id = $1 AND
+			deleted_at IS NULL
+		UNION ALL
+		SELECT
+			users.id,
+			users.referrer_id,
+			users.username,
+			users.created_at,
+			tree.depth + 1
+		FROM
+			users JOIN tree ON users.referrer_id = tree.id
+		WHERE
+			tree.depth < $2 AND
+			users.deleted_at IS NULL
+	)
+	SELECT     
+		id AS user_id,
+		referrer_id,
+		username,
+		created_at AS registered_at,
+		depth
+	FROM 
+		tree
+	ORDER BY
+		depth,
+		created_at,
+		id
+	LIMIT $3
+`
+
+const referralQueryStats = `
+	WITH RECURSIVE tree AS (
+		SELECT
+			id,
+			1 AS depth
+		FROM
+			users
+		WHERE
+			referrer_id = $1 AND
+			deleted_at IS NULL
+		UNION ALL
+		SELECT
+			users.id,
+			tree.depth + 1
+		FROM
+			users JOIN tree ON users.referrer_id = tree.id
+		WHERE
+			tree.depth < $2 AND
+			users.deleted_at IS NULL
+	)
+	SELECT     
+		depth,
+		COUNT(*) AS referees
+	FROM 
+		tree
+	GROUP BY
+		depth
+	ORDER BY
+		depth
+`
+
+const referralQueryRedemptionsByCampaign = `
+	SELECT     
+		COALESCE(campaign, '') AS campaign,
+		COUNT(*) AS redemptions,
+		COUNT(DISTINCT referrer_id) AS referrers
+	FROM 
+		referral_redemption
+	WHERE
+		redeemed_at >= $1 AND
+		redeemed_at < $2
+	GROUP BY
+		campaign
+	ORDER BY
+		redemptions DESC,
+		campaign
+`
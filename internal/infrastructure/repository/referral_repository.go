@@ -0,0 +1,109 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/postgres"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReferralRepository struct {
+	db        postgres.Database
+	txManager postgres.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewReferralRepository(db postgres.Database, txManager postgres.TransactionManager, log *slog.Logger, tracer trace.Tracer) *ReferralRepository {
+	return &ReferralRepository{
+		db:        db,
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *ReferralRepository) ListReferees(ctx context.Context, referrerID uuid.UUID, limit int, offset int) ([]models.Referee, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralRepository.ListReferees")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, referralQueryListReferees, referrerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refereeEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[RefereeEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return refereesToModel(refereeEntityList), nil
+}
+
+func (s *ReferralRepository) Tree(ctx context.Context, referrerID uuid.UUID, maxDepth int, limit int) ([]models.Referee, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralRepository.Tree")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, referralQueryTree, referrerID, maxDepth, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refereeEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[RefereeEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return refereesToModel(refereeEntityList), nil
+}
+
+func (s *ReferralRepository) Stats(ctx context.Context, referrerID uuid.UUID, maxDepth int) (models.ReferralStats, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralRepository.Stats")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, referralQueryStats, referrerID, maxDepth)
+	if err != nil {
+		return models.ReferralStats{}, err
+	}
+	defer rows.Close()
+
+	levelEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[ReferralLevelEntity])
+	if err != nil {
+		return models.ReferralStats{}, err
+	}
+
+	return referralStatsToModel(levelEntityList), nil
+}
+
+func (s *ReferralRepository) RedemptionsByCampaign(ctx context.Context, from time.Time, to time.Time) ([]models.CampaignRedemptions, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralRepository.RedemptionsByCampaign")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, referralQueryRedemptionsByCampaign, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaignEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[CampaignRedemptionsEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return campaignRedemptionsToModel(campaignEntityList), nil
+}
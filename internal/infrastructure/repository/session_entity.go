@@ -8,11 +8,17 @@ import (
 )
 
 type SessionEntity struct {
-	ID           uuid.UUID `db:"id"`
-	UserID       uuid.UUID `db:"user_id"`
-	RefreshToken string    `db:"refresh_token"`
-	ExpiredAt    time.Time `db:"expired_at"`
-	IsRevoked    bool      `db:"is_revoked"`
+	ID                 uuid.UUID `db:"id"`
+	UserID             uuid.UUID `db:"user_id"`
+	RefreshTokenHash   string    `db:"refresh_token_hash"`
+	RefreshTokenPrefix string    `db:"refresh_token_prefix"`
+	ExpiredAt          time.Time `db:"expired_at"`
+	IsRevoked          bool      `db:"is_revoked"`
+	CreatedAt          time.Time `db:"created_at"`
+	LastUsedAt         time.Time `db:"last_used_at"`
+	UserAgent          string    `db:"user_agent"`
+	IPAddress          string    `db:"ip_address"`
+	Device             string    `db:"device"`
 }
 
 func sessionToModel(session *SessionEntity) *models.Session {
@@ -20,19 +26,42 @@ func sessionToModel(session *SessionEntity) *models.Session {
 		ID:     session.ID,
 		UserID: session.UserID,
 		RefreshToken: models.RefreshToken{
-			Token:     session.RefreshToken,
+			Hash:      session.RefreshTokenHash,
+			Prefix:    session.RefreshTokenPrefix,
 			ExpiredAt: session.ExpiredAt,
 			IsRevoked: session.IsRevoked,
 		},
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+		ClientInfo: models.ClientInfo{
+			UserAgent: session.UserAgent,
+			IPAddress: session.IPAddress,
+			Device:    session.Device,
+		},
 	}
 }
 
+func sessionsToModel(sessionEntityList []SessionEntity) []models.Session {
+	sessionList := make([]models.Session, 0, len(sessionEntityList))
+	for _, sessionEntity := range sessionEntityList {
+		sessionList = append(sessionList, *sessionToModel(&sessionEntity))
+	}
+
+	return sessionList
+}
+
 func sessionFromModel(session *models.Session) *SessionEntity {
 	return &SessionEntity{
-		ID:           session.ID,
-		UserID:       session.UserID,
-		RefreshToken: session.RefreshToken.Token,
-		ExpiredAt:    session.RefreshToken.ExpiredAt,
-		IsRevoked:    session.RefreshToken.IsRevoked,
+		ID:                 session.ID,
+		UserID:             session.UserID,
+		RefreshTokenHash:   session.RefreshToken.Hash,
+		RefreshTokenPrefix: session.RefreshToken.Prefix,
+		ExpiredAt:          session.RefreshToken.ExpiredAt,
+		IsRevoked:          session.RefreshToken.IsRevoked,
+		CreatedAt:          session.CreatedAt,
+		LastUsedAt:         session.LastUsedAt,
+		UserAgent:          session.ClientInfo.UserAgent,
+		IPAddress:          session.ClientInfo.IPAddress,
+		Device:             session.ClientInfo.Device,
 	}
 }
@@ -3,18 +3,28 @@ package pgrepo
 const sessionQueryCreate = `
 	INSERT INTO session (
 		user_id,
-		refresh_token,
+		refresh_token_hash,
+		refresh_token_prefix,
 		expired_at,
-		is_revoked
+		is_revoked,
+		user_agent,
+		ip_address,
+		device
 	) VALUES (
-		$1, $2, $3, $4
+		$1, $2, $3, $4, $5, $6, $7, $8
 	)
 	RETURNING 
 		id,
 		user_id,
-		refresh_token,
+		refresh_token_hash,
+		refresh_token_prefix,
 		expired_at,
-		is_revoked
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
 `
 
 const sessionQueryDelete = `
@@ -28,30 +38,111 @@ const sessionQueryDelete = `
 		deleted_at;
 `
 
+const sessionQueryPurge = `
+	DELETE FROM 
+		session
+	WHERE 
+		id IN (
+			SELECT
+				id
+			FROM
+				session
+			WHERE
+				expired_at < $1 OR
+				(is_revoked = TRUE AND last_used_at < $1) OR
+				deleted_at < $1
+			LIMIT $2
+		)
+`
+
 const sessionQueryGetByID = `
 	SELECT     
 		id, 
 		user_id,
-		refresh_token,
+		refresh_token_hash,
+		refresh_token_prefix,
 		expired_at,
-		is_revoked
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
 	FROM 
 		session
 	WHERE
 		id = $1
 `
 
-const sessionQueryGetByRefreshToken = `
+const sessionQueryListActiveByUserID = `
+	SELECT     
+		id, 
+		user_id,
+		refresh_token_hash,
+		refresh_token_prefix,
+		expired_at,
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
+	FROM 
+		session
+	WHERE
+		user_id = $1 AND
+		is_revoked = FALSE AND
+		expired_at > NOW() AND
+		deleted_at IS NULL
+	ORDER BY
+		last_used_at DESC
+`
+
+const sessionQueryGetByRefreshTokenHash = `
 	SELECT     
 		id, 
 		user_id,
-		refresh_token,
+		refresh_token_hash,
+		refresh_token_prefix,
 		expired_at,
-		is_revoked
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
 	FROM 
 		session
 	WHERE
-		refresh_token = $1
+		refresh_token_hash = $1
+`
+
+const sessionQueryGetByRotatedRefreshToken = `
+	SELECT     
+		session.id, 
+		session.user_id,
+		session.refresh_token_hash,
+		session.refresh_token_prefix,
+		session.expired_at,
+		session.is_revoked,
+		session.created_at,
+		session.last_used_at,
+		session.user_agent,
+		session.ip_address,
+		session.device
+	FROM 
+		session JOIN refresh_token_history history ON history.session_id = session.id
+	WHERE
+		history.token_hash = $1
+`
+
+const sessionQueryAddRotatedRefreshToken = `
+	INSERT INTO refresh_token_history (
+		session_id,
+		token_hash
+	) VALUES (
+		$1, $2
+	)
 `
 
 const sessionQueryRevoke = `
@@ -60,7 +151,76 @@ const sessionQueryRevoke = `
 	SET  
 		is_revoked = TRUE
 	WHERE 
-		user_id = $1
+		id = $1
+	RETURNING 
+		id,
+		user_id,
+		refresh_token_hash,
+		refresh_token_prefix,
+		expired_at,
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
+`
+
+const sessionQueryRevokeByUserID = `
+	UPDATE 
+		session 
+	SET  
+		is_revoked = TRUE
+	WHERE 
+		user_id = $1 AND
+		is_revoked = FALSE
+	RETURNING 
+		id,
+		user_id,
+		refresh_token_hash,
+		refresh_token_prefix,
+		expired_at,
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
+`
+
+const sessionQueryRevokeOldestByUserID = `
+	UPDATE 
+		session 
+	SET  
+		is_revoked = TRUE
+	WHERE 
+		id IN (
+			SELECT
+				id
+			FROM
+				session
+			WHERE
+				user_id = $1 AND
+				is_revoked = FALSE AND
+				expired_at > NOW() AND
+				deleted_at IS NULL
+			ORDER BY
+				created_at DESC,
+				id DESC
+			OFFSET $2
+		)
+	RETURNING 
+		id,
+		user_id,
+		refresh_token_hash,
+		refresh_token_prefix,
+		expired_at,
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
 `
 
 const sessionQueryUpdate = `
@@ -68,15 +228,55 @@ const sessionQueryUpdate = `
 		session 
 	SET  
 		user_id = $2,
-		refresh_token = $3,
-		expired_at = $4,
-		is_revoked = $5
+		refresh_token_hash = $3,
+		refresh_token_prefix = $4,
+		expired_at = $5,
+		is_revoked = $6,
+		last_used_at = $7,
+		user_agent = $8,
+		ip_address = $9,
+		device = $10
 	WHERE 
 		id = $1
 	RETURNING 
 		id,
 		user_id,
-		refresh_token,
+		refresh_token_hash,
+		refresh_token_prefix,
+		expired_at,
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
+`
+
+const sessionQueryRotate = `
+	UPDATE 
+		session 
+	SET  
+		refresh_token_hash = $2,
+		refresh_token_prefix = $3,
+		expired_at = $4,
+		last_used_at = $5,
+		user_agent = $6,
+		ip_address = $7,
+		device = $8
+	WHERE 
+		id = $1 AND
+		refresh_token_hash = $9 AND
+		is_revoked = FALSE
+	RETURNING 
+		id,
+		user_id,
+		refresh_token_hash,
+		refresh_token_prefix,
 		expired_at,
-		is_revoked
+		is_revoked,
+		created_at,
+		last_used_at,
+		user_agent,
+		ip_address,
+		device
 `
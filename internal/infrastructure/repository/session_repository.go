@@ -2,12 +2,14 @@ package pgrepo
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
 	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/postgres"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -36,9 +38,13 @@ func (s *SessionRepository) Create(ctx context.Context, session *models.Session)
 
 	args := []any{
 		sessionEntity.UserID,
-		sessionEntity.RefreshToken,
+		sessionEntity.RefreshTokenHash,
+		sessionEntity.RefreshTokenPrefix,
 		sessionEntity.ExpiredAt,
 		sessionEntity.IsRevoked,
+		sessionEntity.UserAgent,
+		sessionEntity.IPAddress,
+		sessionEntity.Device,
 	}
 
 	db := s.txManager.TxOrDB(ctx)
@@ -57,13 +63,13 @@ func (s *SessionRepository) Create(ctx context.Context, session *models.Session)
 	return sessionToModel(&createdSessionEntity), nil
 }
 
-func (s *SessionRepository) GetByRefreshToken(ctx context.Context, refreshToken string) (*models.Session, error) {
-	ctx, span := s.tracer.Start(ctx, "SessionRepository.GetByRefreshToken")
+func (s *SessionRepository) GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*models.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.GetByRefreshTokenHash")
 	defer span.End()
 
 	db := s.txManager.TxOrDB(ctx)
 
-	rows, err := db.Query(ctx, sessionQueryGetByRefreshToken, refreshToken)
+	rows, err := db.Query(ctx, sessionQueryGetByRefreshTokenHash, refreshTokenHash)
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +77,74 @@ func (s *SessionRepository) GetByRefreshToken(ctx context.Context, refreshToken
 
 	sessionEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[SessionEntity])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrSessionNotFound
+		}
+
+		return nil, err
+	}
+
+	return sessionToModel(&sessionEntity), nil
+}
+
+func (s *SessionRepository) GetByRotatedRefreshToken(ctx context.Context, refreshTokenHash string) (*models.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.GetByRotatedRefreshToken")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, sessionQueryGetByRotatedRefreshToken, refreshTokenHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessionEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[SessionEntity])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrSessionNotFound
+		}
+
 		return nil, err
 	}
 
 	return sessionToModel(&sessionEntity), nil
 }
 
+func (s *SessionRepository) AddRotatedRefreshToken(ctx context.Context, sessionID uuid.UUID, refreshTokenHash string) error {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.AddRotatedRefreshToken")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	_, err := db.Exec(ctx, sessionQueryAddRotatedRefreshToken, sessionID, refreshTokenHash)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SessionRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.ListActiveByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, sessionQueryListActiveByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessionEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[SessionEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionsToModel(sessionEntityList), nil
+}
+
 func (s *SessionRepository) GetByID(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
 	ctx, span := s.tracer.Start(ctx, "SessionRepository.GetByID")
 	defer span.End()
@@ -91,6 +159,10 @@ func (s *SessionRepository) GetByID(ctx context.Context, sessionID uuid.UUID) (*
 
 	sessionEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[SessionEntity])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrSessionNotFound
+		}
+
 		return nil, err
 	}
 
@@ -106,9 +178,14 @@ func (s *SessionRepository) Update(ctx context.Context, session *models.Session)
 	args := []any{
 		sessionEntity.ID,
 		sessionEntity.UserID,
-		sessionEntity.RefreshToken,
+		sessionEntity.RefreshTokenHash,
+		sessionEntity.RefreshTokenPrefix,
 		sessionEntity.ExpiredAt,
 		sessionEntity.IsRevoked,
+		sessionEntity.LastUsedAt,
+		sessionEntity.UserAgent,
+		sessionEntity.IPAddress,
+		sessionEntity.Device,
 	}
 
 	db := s.txManager.TxOrDB(ctx)
@@ -127,6 +204,44 @@ func (s *SessionRepository) Update(ctx context.Context, session *models.Session)
 	return sessionToModel(&updatedSessionEntity), nil
 }
 
+func (s *SessionRepository) Rotate(ctx context.Context, session *models.Session, refreshTokenHash string) (*models.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.Rotate")
+	defer span.End()
+
+	sessionEntity := sessionFromModel(session)
+
+	args := []any{
+		sessionEntity.ID,
+		sessionEntity.RefreshTokenHash,
+		sessionEntity.RefreshTokenPrefix,
+		sessionEntity.ExpiredAt,
+		sessionEntity.LastUsedAt,
+		sessionEntity.UserAgent,
+		sessionEntity.IPAddress,
+		sessionEntity.Device,
+		refreshTokenHash,
+	}
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, sessionQueryRotate, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rotatedSessionEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[SessionEntity])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrStaleSession
+		}
+
+		return nil, err
+	}
+
+	return sessionToModel(&rotatedSessionEntity), nil
+}
+
 func (s *SessionRepository) Delete(ctx context.Context, sessionID uuid.UUID) (*time.Time, error) {
 	ctx, span := s.tracer.Start(ctx, "SessionRepository.Delete")
 	defer span.End()
@@ -147,16 +262,76 @@ func (s *SessionRepository) Delete(ctx context.Context, sessionID uuid.UUID) (*t
 	return &deletedAt, nil
 }
 
-func (s *SessionRepository) RevokeByUserID(ctx context.Context, userID uuid.UUID) error {
+func (s *SessionRepository) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.Purge")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	tag, err := db.Exec(ctx, sessionQueryPurge, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (s *SessionRepository) Revoke(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.Revoke")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, sessionQueryRevoke, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessionEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[SessionEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionToModel(&sessionEntity), nil
+}
+
+func (s *SessionRepository) RevokeByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
 	ctx, span := s.tracer.Start(ctx, "SessionRepository.RevokeByUserID")
 	defer span.End()
 
 	db := s.txManager.TxOrDB(ctx)
 
-	_, err := db.Exec(ctx, sessionQueryRevoke, userID)
+	rows, err := db.Query(ctx, sessionQueryRevokeByUserID, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
+	sessionEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[SessionEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionsToModel(sessionEntityList), nil
+}
+
+func (s *SessionRepository) RevokeOldestByUserID(ctx context.Context, userID uuid.UUID, keep int) ([]models.Session, error) {
+	ctx, span := s.tracer.Start(ctx, "SessionRepository.RevokeOldestByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, sessionQueryRevokeOldestByUserID, userID, keep)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessionEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[SessionEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionsToModel(sessionEntityList), nil
 }
@@ -0,0 +1,46 @@
+package pgrepo
+
+import (
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/tokens"
+)
+
+type SigningKeyEntity struct {
+	ID          string     `db:"id"`
+	Algorithm   string     `db:"algorithm"`
+	PrivateKey  []byte     `db:"private_key"`
+	ActivatesAt time.Time  `db:"activates_at"`
+	RetiredAt   *time.Time `db:"retired_at"`
+}
+
+func signingKeyToModel(signingKey *SigningKeyEntity) (tokens.StoredKey, error) {
+	key, err := tokens.UnmarshalSigningKey(signingKey.Algorithm, signingKey.PrivateKey)
+	if err != nil {
+		return tokens.StoredKey{}, err
+	}
+
+	storedKey := tokens.StoredKey{
+		Key:         key,
+		ActivatesAt: signingKey.ActivatesAt,
+	}
+
+	if signingKey.RetiredAt != nil {
+		storedKey.RetiredAt = *signingKey.RetiredAt
+	}
+
+	return storedKey, nil
+}
+
+func signingKeyFromModel(key tokens.SigningKey) (*SigningKeyEntity, error) {
+	privateKey, err := tokens.MarshalSigningKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKeyEntity{
+		ID:         key.ID,
+		Algorithm:  key.Method.Alg(),
+		PrivateKey: privateKey,
+	}, nil
+}
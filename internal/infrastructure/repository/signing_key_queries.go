@@ -0,0 +1,49 @@
+package pgrepo
+
+const signingKeyQueryList = `
+	SELECT     
+		id,
+		algorithm,
+		private_key,
+		activates_at,
+		retired_at
+	FROM 
+		signing_key
+	ORDER BY
+		activates_at
+`
+
+const signingKeyQueryAdd = `
+	INSERT INTO signing_key (
+		id,
+		algorithm,
+		private_key,
+		activates_at
+	) VALUES (
+		$1, $2, $3, $4
+	)
+	ON CONFLICT (id) DO NOTHING
+`
+
+// a key unknown so far is stored as activated long ago, it only has to stay
+// accepted until its retention ends
+const signingKeyQueryRetire = `
+	INSERT INTO signing_key (
+		id,
+		algorithm,
+		private_key,
+		activates_at,
+		retired_at
+	) VALUES (
+		$1, $2, $3, 'epoch', $4
+	)
+	ON CONFLICT (id) DO UPDATE SET 
+		retired_at = COALESCE(signing_key.retired_at, EXCLUDED.retired_at)
+`
+
+const signingKeyQueryDelete = `
+	DELETE FROM 
+		signing_key
+	WHERE 
+		id = ANY($1)
+`
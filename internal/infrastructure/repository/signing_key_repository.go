@@ -0,0 +1,123 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/postgres"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/tokens"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SigningKeyRepository is the key store of the token signing keys.
+type SigningKeyRepository struct {
+	db        postgres.Database
+	txManager postgres.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewSigningKeyRepository(db postgres.Database, txManager postgres.TransactionManager, log *slog.Logger, tracer trace.Tracer) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		db:        db,
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *SigningKeyRepository) List(ctx context.Context) ([]tokens.StoredKey, error) {
+	ctx, span := s.tracer.Start(ctx, "SigningKeyRepository.List")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	rows, err := db.Query(ctx, signingKeyQueryList)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signingKeyEntityList, err := pgx.CollectRows(rows, pgx.RowToStructByName[SigningKeyEntity])
+	if err != nil {
+		return nil, err
+	}
+
+	storedKeyList := make([]tokens.StoredKey, 0, len(signingKeyEntityList))
+	for _, signingKeyEntity := range signingKeyEntityList {
+		storedKey, err := signingKeyToModel(&signingKeyEntity)
+		if err != nil {
+			return nil, fmt.Errorf("decode signing key %s: %w", signingKeyEntity.ID, err)
+		}
+
+		storedKeyList = append(storedKeyList, storedKey)
+	}
+
+	return storedKeyList, nil
+}
+
+func (s *SigningKeyRepository) Add(ctx context.Context, key tokens.StoredKey) error {
+	ctx, span := s.tracer.Start(ctx, "SigningKeyRepository.Add")
+	defer span.End()
+
+	signingKeyEntity, err := signingKeyFromModel(key.Key)
+	if err != nil {
+		return err
+	}
+
+	db := s.txManager.TxOrDB(ctx)
+
+	args := []any{
+		signingKeyEntity.ID,
+		signingKeyEntity.Algorithm,
+		signingKeyEntity.PrivateKey,
+		key.ActivatesAt.UTC(),
+	}
+
+	if _, err := db.Exec(ctx, signingKeyQueryAdd, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SigningKeyRepository) Retire(ctx context.Context, key tokens.SigningKey, retiredAt time.Time) error {
+	ctx, span := s.tracer.Start(ctx, "SigningKeyRepository.Retire")
+	defer span.End()
+
+	signingKeyEntity, err := signingKeyFromModel(key)
+	if err != nil {
+		return err
+	}
+
+	db := s.txManager.TxOrDB(ctx)
+
+	args := []any{
+		signingKeyEntity.ID,
+		signingKeyEntity.Algorithm,
+		signingKeyEntity.PrivateKey,
+		retiredAt.UTC(),
+	}
+
+	if _, err := db.Exec(ctx, signingKeyQueryRetire, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SigningKeyRepository) Delete(ctx context.Context, keyIDs []string) error {
+	ctx, span := s.tracer.Start(ctx, "SigningKeyRepository.Delete")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+
+	if _, err := db.Exec(ctx, signingKeyQueryDelete, keyIDs); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -6,17 +6,31 @@ import (
 )
 
 type UserEntity struct {
-	ID           uuid.UUID `db:"id"`
-	ReferrerID   uuid.UUID `db:"referrer_id"`
-	Username     string    `db:"username"`
-	HashPassword string    `db:"hash_password"`
+	ID                   uuid.UUID  `db:"id"`
+	ReferrerID           *uuid.UUID `db:"referrer_id"`
+	Username             string     `db:"username"`
+	HashPassword         string     `db:"hash_password"`
+	Roles                []string   `db:"roles"`
+	Email                *string    `db:"email"`
+	EmailVerified        bool       `db:"email_verified"`
+	ReferralDiscoverable bool       `db:"referral_discoverable"`
 }
 
 func userToModel(user *UserEntity) *models.User {
+	var email string
+	if user.Email != nil {
+		email = *user.Email
+	}
+
 	return &models.User{
-		ID:           user.ID,
-		Username:     user.Username,
-		HashPassword: user.HashPassword,
+		ID:                   user.ID,
+		Username:             user.Username,
+		HashPassword:         user.HashPassword,
+		Roles:                user.Roles,
+		ReferrerID:           user.ReferrerID,
+		Email:                email,
+		EmailVerified:        user.EmailVerified,
+		ReferralDiscoverable: user.ReferralDiscoverable,
 	}
 }
 
@@ -30,9 +44,19 @@ func usersToModel(userEntityList []UserEntity) []models.User {
 }
 
 func userFromModel(user *models.User) *UserEntity {
+	var email *string
+	if user.Email != "" {
+		email = &user.Email
+	}
+
 	return &UserEntity{
-		ID:           user.ID,
-		Username:     user.Username,
-		HashPassword: user.HashPassword,
+		ID:                   user.ID,
+		Username:             user.Username,
+		HashPassword:         user.HashPassword,
+		Roles:                user.Roles,
+		ReferrerID:           user.ReferrerID,
+		Email:                email,
+		EmailVerified:        user.EmailVerified,
+		ReferralDiscoverable: user.ReferralDiscoverable,
 	}
 }
@@ -4,15 +4,23 @@ const userQueryCreate = `
 	INSERT INTO users (
 		username,
 		referrer_id,
-		hash_password
+		hash_password,
+		roles,
+		email,
+		email_verified,
+		referral_discoverable
 	) VALUES (
-	 	$1, $2, $3
+	 	$1, $2, $3, $4, $5, $6, $7
 	)
 	RETURNING 
 		id,
 		username,
 		referrer_id,
-		hash_password
+		hash_password,
+		roles,
+		email,
+		email_verified,
+		referral_discoverable
 `
 
 const userQueryDelete = `
@@ -31,11 +39,16 @@ const userQueryGetByID = `
 		id, 
 		username,
 		referrer_id,
-		hash_password
+		hash_password,
+		roles,
+		email,
+		email_verified,
+		referral_discoverable
 	FROM 
 		users
 	WHERE
-		id = $1
+		id = $1 AND
+		deleted_at IS NULL
 `
 
 const userQueryGetByUsername = `
@@ -43,11 +56,16 @@ const userQueryGetByUsername = `
 		id,
 		username,
 		referrer_id,
-		hash_password
+		hash_password,
+		roles,
+		email,
+		email_verified,
+		referral_discoverable
 	FROM 
 		users
 	WHERE
-		username = $1
+		username = $1 AND
+		deleted_at IS NULL
 `
 
 const userQueryList = `
@@ -55,7 +73,11 @@ const userQueryList = `
 		id,
 		username,
 		referrer_id,
-		hash_password
+		hash_password,
+		roles,
+		email,
+		email_verified,
+		referral_discoverable
 	FROM 
 		users
 `
@@ -66,12 +88,20 @@ const userQueryUpdate = `
 	SET  
 		username = $2,
 		referrer_id = $3,
-		hash_password = $4
+		hash_password = $4,
+		roles = $5,
+		email = $6,
+		email_verified = $7,
+		referral_discoverable = $8
 	WHERE 
 		id = $1
 	RETURNING 
 		id,
 		username,
 		referrer_id,
-		hash_password
+		hash_password,
+		roles,
+		email,
+		email_verified,
+		referral_discoverable
 `
@@ -2,12 +2,14 @@ package pgrepo
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
 	"github.com/rozhnof/stakewolle-auth-service/internal/infrastructure/database/postgres"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -36,7 +38,17 @@ func (s *UserRepository) Create(ctx context.Context, user *models.User) (*models
 
 	db := s.txManager.TxOrDB(ctx)
 
-	rows, err := db.Query(ctx, userQueryCreate, userEntity.Username, userEntity.ReferrerID, userEntity.HashPassword)
+	args := []any{
+		userEntity.Username,
+		userEntity.ReferrerID,
+		userEntity.HashPassword,
+		userEntity.Roles,
+		userEntity.Email,
+		userEntity.EmailVerified,
+		userEntity.ReferralDiscoverable,
+	}
+
+	rows, err := db.Query(ctx, userQueryCreate, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +76,10 @@ func (s *UserRepository) GetByID(ctx context.Context, userID uuid.UUID) (*models
 
 	userEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[UserEntity])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrUserNotFound
+		}
+
 		return nil, err
 	}
 
@@ -84,6 +100,10 @@ func (s *UserRepository) GetByUsername(ctx context.Context, username string) (*m
 
 	userEntity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[UserEntity])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrUserNotFound
+		}
+
 		return nil, err
 	}
 
@@ -118,7 +138,18 @@ func (s *UserRepository) Update(ctx context.Context, user *models.User) (*models
 
 	db := s.txManager.TxOrDB(ctx)
 
-	rows, err := db.Query(ctx, userQueryUpdate, userEntity.Username, userEntity.ReferrerID, userEntity.HashPassword)
+	args := []any{
+		userEntity.ID,
+		userEntity.Username,
+		userEntity.ReferrerID,
+		userEntity.HashPassword,
+		userEntity.Roles,
+		userEntity.Email,
+		userEntity.EmailVerified,
+		userEntity.ReferralDiscoverable,
+	}
+
+	rows, err := db.Query(ctx, userQueryUpdate, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -144,8 +175,12 @@ func (s *UserRepository) Delete(ctx context.Context, userID uuid.UUID) (*time.Ti
 	}
 	defer rows.Close()
 
-	deletedAt, err := pgx.RowTo[time.Time](rows)
+	deletedAt, err := pgx.CollectOneRow(rows, pgx.RowTo[time.Time])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrUserNotFound
+		}
+
 		return nil, err
 	}
 
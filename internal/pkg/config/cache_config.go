@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+type CacheConfig struct {
+	UserTTL         time.Duration `yaml:"user_ttl"          env-default:"1h"`
+	UserNegativeTTL time.Duration `yaml:"user_negative_ttl" env-default:"30s"`
+	UserLocalSize   int           `yaml:"user_local_size"   env-default:"10000"`
+	UserLocalTTL    time.Duration `yaml:"user_local_ttl"    env-default:"10s"`
+	SessionTTL      time.Duration `yaml:"session_ttl"       env-default:"1h"`
+	EarlyRefresh    float64       `yaml:"early_refresh"     env-default:"1"`
+}
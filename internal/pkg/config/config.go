@@ -5,10 +5,16 @@ import (
 )
 
 type Config struct {
-	Mode     string       `yaml:"mode"    env-required:"true"`
-	Server   ServerConfig `yaml:"server"  env-required:"true"`
-	Logger   LoggerConfig `yaml:"logging" env-required:"true"`
-	Tokens   TokensConfig `yaml:"tokens"  env-required:"true"`
+	Mode     string         `yaml:"mode"    env-required:"true"`
+	Server   ServerConfig   `yaml:"server"  env-required:"true"`
+	Logger   LoggerConfig   `yaml:"logging" env-required:"true"`
+	Tokens   TokensConfig   `yaml:"tokens"  env-required:"true"`
+	Sessions SessionsConfig `yaml:"sessions"`
+	Cache    CacheConfig    `yaml:"cache"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	OAuth    OAuthConfig    `yaml:"oauth"`
+	Referral ReferralConfig `yaml:"referral"`
+	Events   EventsConfig   `yaml:"events"`
 	Postgres PostgresConfig
 	Redis    RedisConfig
 }
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type EventsConfig struct {
+	Stream         string        `yaml:"stream"           env-default:"auth:events"`
+	StreamMaxLen   int64         `yaml:"stream_max_len"   env-default:"1000000"`
+	RelayInterval  time.Duration `yaml:"relay_interval"   env-default:"1s"`
+	RelayBatchSize int           `yaml:"relay_batch_size" env-default:"100"`
+}
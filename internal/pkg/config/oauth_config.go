@@ -0,0 +1,10 @@
+package config
+
+type OAuthConfig struct {
+	Clients []OAuthClientConfig `yaml:"clients"`
+}
+
+type OAuthClientConfig struct {
+	ID         string `yaml:"id"`
+	SecretHash string `yaml:"secret_hash"`
+}
@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+type ReferralConfig struct {
+	CodeTTL      time.Duration `yaml:"code_ttl"       env-default:"720h"`
+	MaxCodeTTL   time.Duration `yaml:"max_code_ttl"   env-default:"8760h"`
+	CodeLength   int           `yaml:"code_length"    env-default:"8"`
+	BlockedWords []string      `yaml:"blocked_words"`
+	LookupLimit  int64         `yaml:"lookup_limit"   env-default:"30"`
+	LookupWindow time.Duration `yaml:"lookup_window"  env-default:"1m"`
+	TreeMaxDepth int           `yaml:"tree_max_depth" env-default:"5"`
+	TreeMaxNodes int           `yaml:"tree_max_nodes" env-default:"1000"`
+}
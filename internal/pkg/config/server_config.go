@@ -1,5 +1,12 @@
 package config
 
+import "time"
+
 type ServerConfig struct {
 	Address string `yaml:"address" env-required:"true"`
+	// MetricsAddress serves /metrics apart from the public API. It must only
+	// be reachable from the internal network.
+	MetricsAddress  string        `yaml:"metrics_address"  env-default:":9090"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"10s"`
+	TrustedProxies  []string      `yaml:"trusted_proxies"  env:"TRUSTED_PROXIES" env-separator:","`
 }
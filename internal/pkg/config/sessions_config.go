@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+type SessionsConfig struct {
+	Policy           string        `yaml:"policy"             env-default:"unlimited"`
+	MaxSessions      int           `yaml:"max_sessions"`
+	CleanupInterval  time.Duration `yaml:"cleanup_interval"`
+	CleanupRetention time.Duration `yaml:"cleanup_retention"  env-default:"720h"`
+	CleanupBatchSize int           `yaml:"cleanup_batch_size" env-default:"1000"`
+}
@@ -1,6 +1,19 @@
 package config
 
+import "time"
+
 type TokensConfig struct {
-	AccessTokenTTL  string `yaml:"access_ttl"  env-required:"true"`
-	RefreshTokenTTL string `yaml:"refresh_ttl" env-required:"true"`
+	AccessTokenTTL   time.Duration `yaml:"access_ttl"        env-required:"true"`
+	RefreshTokenTTL  time.Duration `yaml:"refresh_ttl"       env-required:"true"`
+	IdleTimeout      time.Duration `yaml:"idle_timeout"`
+	MaxLifetime      time.Duration `yaml:"max_lifetime"`
+	Issuer           string        `yaml:"issuer"            env-default:"auth-service"`
+	Audience         []string      `yaml:"audience"`
+	ClockSkew        time.Duration `yaml:"clock_skew"        env-default:"30s"`
+	SigningAlgorithm string        `yaml:"signing_algorithm" env-default:"HS512"`
+	SigningKeyPath   string        `yaml:"signing_key_path"  env:"SIGNING_KEY_PATH"`
+	RetiredKeyPaths  []string      `yaml:"retired_key_paths" env:"RETIRED_SIGNING_KEY_PATHS" env-separator:","`
+	RotationInterval time.Duration `yaml:"rotation_interval"`
+	KeyPublishLead   time.Duration `yaml:"key_publish_lead"  env-default:"10m"`
+	KeySyncInterval  time.Duration `yaml:"key_sync_interval" env-default:"1m"`
 }
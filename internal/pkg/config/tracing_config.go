@@ -0,0 +1,6 @@
+package config
+
+type TracingConfig struct {
+	ServiceName string `yaml:"service_name" env-default:"auth-service"`
+	Endpoint    string `yaml:"endpoint"     env:"TRACING_ENDPOINT"`
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+func NewLogger(level string, path string) (*slog.Logger, io.Closer, error) {
+	var slogLevel slog.Level
+	if err := slogLevel.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		return nil, nil, fmt.Errorf("parse log level: %w", err)
+	}
+
+	var (
+		out    io.Writer = os.Stdout
+		closer io.Closer = io.NopCloser(nil)
+	)
+
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open log file: %w", err)
+		}
+
+		out = file
+		closer = file
+	}
+
+	handler := slog.NewJSONHandler(out, &slog.HandlerOptions{
+		Level: slogLevel,
+	})
+
+	return slog.New(handler), closer, nil
+}
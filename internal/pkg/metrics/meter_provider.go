@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// NewMeterProvider creates a meter provider exporting to a dedicated
+// Prometheus registry, installs it as the global one and returns the handler
+// serving the registry.
+func NewMeterProvider() (*sdkmetric.MeterProvider, http.Handler, error) {
+	registry := prometheus.NewRegistry()
+
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create metric exporter: %w", err)
+	}
+
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+
+	otel.SetMeterProvider(mp)
+
+	return mp, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
@@ -0,0 +1,67 @@
+package refcode
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed blocklist.txt
+var blocklist string
+
+// leet maps digits and symbols to the letters they are used in place of, so
+// that e.g. "5H1T" is caught as well.
+var leet = strings.NewReplacer(
+	"0", "O",
+	"1", "I",
+	"3", "E",
+	"4", "A",
+	"5", "S",
+	"7", "T",
+	"8", "B",
+	"@", "A",
+	"$", "S",
+)
+
+// Filter tells whether a code contains an offensive word. Matching is by
+// substring after removing separators and undoing leetspeak, so a word is
+// caught inside a longer code as well. Words that are common inside harmless
+// ones, like RAPE in GRAPE or COCK in PEACOCK, are left out of the built-in
+// blocklist. The remaining words can still match rare words and names, which
+// rejects such codes.
+type Filter struct {
+	words []string
+}
+
+// NewFilter creates a filter with the built-in blocklist and the extra words.
+func NewFilter(extraWords ...string) *Filter {
+	words := make([]string, 0)
+
+	for _, word := range append(strings.Fields(blocklist), extraWords...) {
+		if word = normalize(word); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return &Filter{
+		words: words,
+	}
+}
+
+func (f *Filter) Profane(code string) bool {
+	code = normalize(code)
+
+	for _, word := range f.words {
+		if strings.Contains(code, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalize(s string) string {
+	s = strings.ToUpper(s)
+	s = strings.NewReplacer("-", "", "_", "", ".", "", " ", "").Replace(s)
+
+	return leet.Replace(s)
+}
@@ -0,0 +1,69 @@
+package refcode
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// alphabet leaves out characters that are easily confused, such as 0 and O or
+// 1 and I, so codes can be read out and typed back
+const alphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// MaxLength is the longest code the referral_code.code column holds.
+const MaxLength = 36
+
+// maxAttempts bounds the codes drawn until one passes the filter; with a
+// sensible length nearly every code passes on the first attempt.
+const maxAttempts = 10
+
+var ErrNoCleanCode = errors.New("no clean referral code generated")
+
+// Generator draws random short codes that pass a profanity filter.
+type Generator struct {
+	length int
+	filter *Filter
+}
+
+func NewGenerator(length int, filter *Filter) (*Generator, error) {
+	if length < 1 || length > MaxLength {
+		return nil, fmt.Errorf("invalid code length %d, must be between 1 and %d", length, MaxLength)
+	}
+
+	return &Generator{
+		length: length,
+		filter: filter,
+	}, nil
+}
+
+func (g *Generator) Generate() (string, error) {
+	for range maxAttempts {
+		code, err := g.draw()
+		if err != nil {
+			return "", err
+		}
+
+		if !g.filter.Profane(code) {
+			return code, nil
+		}
+	}
+
+	return "", ErrNoCleanCode
+}
+
+func (g *Generator) draw() (string, error) {
+	code := make([]byte, g.length)
+	max := big.NewInt(int64(len(alphabet)))
+
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		code[i] = alphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
@@ -1,11 +1,31 @@
 package secrets
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
-const secretKeyEnv = "SECRET_KEY"
+const (
+	secretKeyEnv        = "SECRET_KEY"
+	retiredSecretKeyEnv = "RETIRED_SECRET_KEYS"
+)
 
 type SecretManager struct{}
 
 func (m SecretManager) SecretKey() []byte {
 	return []byte(os.Getenv(secretKeyEnv))
 }
+
+// RetiredSecretKeys returns the comma separated secrets that were used for
+// signing before the current one and must still be accepted for a while.
+func (m SecretManager) RetiredSecretKeys() [][]byte {
+	var keys [][]byte
+
+	for _, key := range strings.Split(os.Getenv(retiredSecretKeyEnv), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, []byte(key))
+		}
+	}
+
+	return keys
+}
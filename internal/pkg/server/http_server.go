@@ -26,6 +26,6 @@ func (s *HTTPServer) Run() error {
 	return s.srv.ListenAndServe()
 }
 
-func (s *HTTPServer) Shutdown() error {
-	return s.srv.Shutdown(context.Background())
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
 }
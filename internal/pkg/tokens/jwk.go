@@ -0,0 +1,80 @@
+package tokens
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public JSON Web Key as described in RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newJWK(key SigningKey) (JWK, error) {
+	jwk := JWK{
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: key.Method.Alg(),
+	}
+
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeBase64URL(publicKey.N.Bytes())
+		jwk.E = encodeBase64URL(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+
+		jwk.KeyType = "EC"
+		jwk.Curve = publicKey.Curve.Params().Name
+		jwk.X = encodeBase64URL(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBase64URL(publicKey.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encodeBase64URL(publicKey)
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", key.PublicKey)
+	}
+
+	return jwk, nil
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of the key.
+func (k JWK) Thumbprint() string {
+	var members string
+
+	switch k.KeyType {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, k.E, k.KeyType, k.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, k.Curve, k.KeyType, k.X, k.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, k.Curve, k.KeyType, k.X)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+
+	return encodeBase64URL(sum[:])
+}
+
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,164 @@
+package tokens
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type ringKey struct {
+	key         SigningKey
+	activatesAt time.Time
+	// retiredAt is zero while no newer key is scheduled
+	retiredAt time.Time
+}
+
+// KeyRing holds the signing keys of the key store. The active key is the most
+// recently activated one; keys scheduled to activate later are already
+// published, and a retired key is still published and accepted for
+// verification until retention has elapsed since its retirement, so tokens
+// signed with it can expire naturally.
+type KeyRing struct {
+	mu        sync.RWMutex
+	keys      []ringKey
+	retention time.Duration
+}
+
+func NewKeyRing(retention time.Duration) *KeyRing {
+	return &KeyRing{
+		retention: retention,
+	}
+}
+
+// Set replaces the keys of the ring with the stored ones.
+func (r *KeyRing) Set(stored []StoredKey) {
+	keys := scheduleKeys(stored)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys = keys
+}
+
+// scheduleKeys orders the keys by activation and works out when each one is
+// retired: when it was retired explicitly, or else when a newer key
+// activates.
+func scheduleKeys(stored []StoredKey) []ringKey {
+	keys := make([]ringKey, 0, len(stored))
+	for _, key := range stored {
+		keys = append(keys, ringKey{
+			key:         key.Key,
+			activatesAt: key.ActivatesAt,
+			retiredAt:   key.RetiredAt,
+		})
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].activatesAt.Before(keys[j].activatesAt)
+	})
+
+	for i := range keys {
+		if !keys[i].retiredAt.IsZero() {
+			continue
+		}
+
+		for _, next := range keys[i+1:] {
+			if next.activatesAt.After(keys[i].activatesAt) {
+				keys[i].retiredAt = next.activatesAt
+				break
+			}
+		}
+	}
+
+	return keys
+}
+
+func (r *KeyRing) ActiveKey() SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.activeKey(time.Now()).key
+}
+
+// activeKey returns the most recently activated key that is not retired. If
+// every activated key is retired, the most recent one is still used rather
+// than not signing at all.
+func (r *KeyRing) activeKey(now time.Time) ringKey {
+	var active, latest ringKey
+
+	for _, key := range r.keys {
+		if key.activatesAt.After(now) {
+			break
+		}
+
+		latest = key
+
+		if key.retiredAt.IsZero() || key.retiredAt.After(now) {
+			active = key
+		}
+	}
+
+	if active.key.ID == "" {
+		return latest
+	}
+
+	return active
+}
+
+// VerificationKey returns the key with the given ID if it is published.
+func (r *KeyRing) VerificationKey(keyID string) (SigningKey, bool) {
+	for _, key := range r.Keys() {
+		if key.ID == keyID {
+			return key, true
+		}
+	}
+
+	return SigningKey{}, false
+}
+
+// Keys returns the active key followed by the keys scheduled to activate and
+// the retired keys that are still accepted.
+func (r *KeyRing) Keys() []SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	active := r.activeKey(now)
+
+	keys := make([]SigningKey, 0, len(r.keys))
+	if active.key.ID != "" {
+		keys = append(keys, active.key)
+	}
+
+	for _, key := range r.keys {
+		if key.key.ID != active.key.ID && !expired(key, r.retention, now) {
+			keys = append(keys, key.key)
+		}
+	}
+
+	return keys
+}
+
+func expired(key ringKey, retention time.Duration, now time.Time) bool {
+	return !key.retiredAt.IsZero() && !key.retiredAt.Add(retention).After(now)
+}
+
+// JWKS returns the public part of every published key. Symmetric keys are
+// never published.
+func (r *KeyRing) JWKS() JWKS {
+	jwks := JWKS{
+		Keys: []JWK{},
+	}
+
+	for _, key := range r.Keys() {
+		if key.PublicKey == nil {
+			continue
+		}
+
+		if jwk, err := newJWK(key); err == nil {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	return jwks
+}
@@ -0,0 +1,177 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/repository"
+)
+
+// keyRotatorLockKey is the advisory lock that elects the replica generating
+// signing keys. It must not be reused by any other job.
+const keyRotatorLockKey int64 = 1002
+
+// JWKSMaxAge is how long clients may cache the JWKS.
+const JWKSMaxAge = 5 * time.Minute
+
+type KeyRotatorConfig struct {
+	Algorithm string
+	// Interval is how long a key signs tokens before the next one activates.
+	Interval time.Duration
+	// PublishLead is how long a new key is published before it activates.
+	PublishLead time.Duration
+	// SyncInterval is how often the keys are reloaded from the store.
+	SyncInterval time.Duration
+}
+
+// Valid checks that a new key reaches the JWKS of every replica at least one
+// JWKS cache lifetime before it signs anything.
+func (c KeyRotatorConfig) Valid() error {
+	if c.Interval <= 0 {
+		return errors.New("rotation interval must be positive")
+	}
+
+	if c.SyncInterval <= 0 {
+		return errors.New("key sync interval must be positive")
+	}
+
+	if c.PublishLead < JWKSMaxAge+c.SyncInterval {
+		return errors.New("key publish lead must cover the JWKS max age and the key sync interval")
+	}
+
+	return nil
+}
+
+// KeyRotator keeps the key ring in sync with the key store and, on the
+// replica holding the advisory lock, schedules a new key when the active one
+// is due to be replaced. Every replica switches to the new key when it
+// activates, as they all load the same schedule.
+type KeyRotator struct {
+	cfg                KeyRotatorConfig
+	ring               *KeyRing
+	store              KeyStore
+	transactionManager repository.TransactionManager
+	locker             repository.Locker
+	log                *slog.Logger
+}
+
+func NewKeyRotator(
+	cfg KeyRotatorConfig,
+	ring *KeyRing,
+	store KeyStore,
+	transactionManager repository.TransactionManager,
+	locker repository.Locker,
+	log *slog.Logger,
+) (*KeyRotator, error) {
+	if err := cfg.Valid(); err != nil {
+		return nil, err
+	}
+
+	return &KeyRotator{
+		cfg:                cfg,
+		ring:               ring,
+		store:              store,
+		transactionManager: transactionManager,
+		locker:             locker,
+		log:                log,
+	}, nil
+}
+
+func (r *KeyRotator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.rotate(ctx); err != nil && ctx.Err() == nil {
+				r.log.Error("rotate signing keys", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (r *KeyRotator) rotate(ctx context.Context) error {
+	if err := r.transactionManager.WithTransaction(ctx, func(ctx context.Context) error {
+		locked, err := r.locker.TryLock(ctx, keyRotatorLockKey)
+		if err != nil || !locked {
+			return err
+		}
+
+		return r.schedule(ctx, time.Now())
+	}); err != nil {
+		return err
+	}
+
+	stored, err := r.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.ring.Set(stored)
+
+	return nil
+}
+
+// schedule stores the next key once the latest one is due to be replaced
+// within the publish lead, and deletes the keys no longer accepted.
+func (r *KeyRotator) schedule(ctx context.Context, now time.Time) error {
+	stored, err := r.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := scheduleKeys(stored)
+	if len(keys) == 0 {
+		return errors.New("no signing keys stored")
+	}
+
+	var expiredKeyIDs []string
+	for _, key := range keys {
+		if expired(key, r.ring.retention, now) {
+			expiredKeyIDs = append(expiredKeyIDs, key.key.ID)
+		}
+	}
+
+	if len(expiredKeyIDs) > 0 {
+		if err := r.store.Delete(ctx, expiredKeyIDs); err != nil {
+			return err
+		}
+	}
+
+	latest := keys[len(keys)-1]
+
+	due := latest.activatesAt.Add(r.cfg.Interval)
+	if now.Before(due.Add(-r.cfg.PublishLead)) {
+		return nil
+	}
+
+	key, err := GenerateSigningKey(r.cfg.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	activatesAt := now.Add(r.cfg.PublishLead)
+	if due.After(activatesAt) {
+		activatesAt = due
+	}
+
+	if err := r.store.Add(ctx, StoredKey{
+		Key:         key,
+		ActivatesAt: activatesAt,
+	}); err != nil {
+		return err
+	}
+
+	r.log.Info("signing key scheduled",
+		slog.String("previous_kid", latest.key.ID),
+		slog.String("kid", key.ID),
+		slog.Time("activates_at", activatesAt),
+	)
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package tokens
+
+import (
+	"context"
+	"time"
+)
+
+// StoredKey is a signing key with its schedule. A key signs tokens from
+// ActivatesAt until the next key activates or it is retired, and is
+// published from the moment it is stored, so verifiers know it before it
+// signs anything.
+type StoredKey struct {
+	Key         SigningKey
+	ActivatesAt time.Time
+	// RetiredAt is set for keys retired explicitly, a key superseded by a
+	// newer one is retired when that key activates.
+	RetiredAt time.Time
+}
+
+// KeyStore keeps signing keys shared by all replicas, so that they sign with
+// the same key and keep their keys across restarts.
+type KeyStore interface {
+	List(ctx context.Context) ([]StoredKey, error)
+	// Add stores the key unless a key with its ID is stored already.
+	Add(ctx context.Context, key StoredKey) error
+	// Retire stores the key as retired at the given time, unless it was
+	// retired before.
+	Retire(ctx context.Context, key SigningKey, retiredAt time.Time) error
+	Delete(ctx context.Context, keyIDs []string) error
+}
@@ -0,0 +1,109 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/secrets"
+)
+
+type KeyRingConfig struct {
+	Algorithm       string
+	KeyPath         string
+	RetiredKeyPaths []string
+	Retention       time.Duration
+	// Rotating means the KeyRotator schedules new keys, the configured key
+	// then only seeds an empty store. Otherwise a newly configured key
+	// activates as soon as it is loaded.
+	Rotating bool
+}
+
+// LoadKeyRing stores the configured keys and builds a key ring from the key
+// store. For HS512 the keys come from the secret manager, for asymmetric
+// algorithms they are read from PEM files. Configured retired keys are stored
+// with the time they were first seen retired, so a restart does not extend
+// their retention.
+func LoadKeyRing(ctx context.Context, cfg KeyRingConfig, secretManager secrets.SecretManager, store KeyStore) (*KeyRing, error) {
+	active, retired, err := loadConfiguredKeys(cfg, secretManager)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	stored, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.Rotating || len(stored) == 0 {
+		if err := store.Add(ctx, StoredKey{Key: active, ActivatesAt: now}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range retired {
+		if err := store.Retire(ctx, key, now); err != nil {
+			return nil, err
+		}
+	}
+
+	stored, err = store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ring := NewKeyRing(cfg.Retention)
+	ring.Set(stored)
+
+	return ring, nil
+}
+
+func loadConfiguredKeys(cfg KeyRingConfig, secretManager secrets.SecretManager) (SigningKey, []SigningKey, error) {
+	if cfg.Algorithm == AlgorithmHS512 {
+		secret := secretManager.SecretKey()
+		if len(secret) == 0 {
+			return SigningKey{}, nil, errors.New("empty secret key")
+		}
+
+		var retired []SigningKey
+		for _, retiredSecret := range secretManager.RetiredSecretKeys() {
+			retired = append(retired, NewSecretSigningKey(retiredSecret))
+		}
+
+		return NewSecretSigningKey(secret), retired, nil
+	}
+
+	if cfg.KeyPath == "" {
+		return SigningKey{}, nil, fmt.Errorf("signing key path is required for %s", cfg.Algorithm)
+	}
+
+	active, err := loadSigningKey(cfg.Algorithm, cfg.KeyPath)
+	if err != nil {
+		return SigningKey{}, nil, err
+	}
+
+	var retired []SigningKey
+	for _, path := range cfg.RetiredKeyPaths {
+		key, err := loadSigningKey(cfg.Algorithm, path)
+		if err != nil {
+			return SigningKey{}, nil, err
+		}
+
+		retired = append(retired, key)
+	}
+
+	return active, retired, nil
+}
+
+func loadSigningKey(algorithm string, keyPath string) (SigningKey, error) {
+	pemData, err := os.ReadFile(keyPath)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("read signing key: %w", err)
+	}
+
+	return ParseSigningKey(algorithm, pemData)
+}
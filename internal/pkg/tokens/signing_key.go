@@ -0,0 +1,182 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AlgorithmHS512 = "HS512"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrKeyAlgorithmMismatch = errors.New("key type does not match signing algorithm")
+)
+
+// SigningKey is a key used to sign access tokens. For asymmetric algorithms
+// PublicKey is set and the key is published in the JWKS, for HMAC it is nil.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey any
+	PublicKey  crypto.PublicKey
+}
+
+func NewSecretSigningKey(secret []byte) SigningKey {
+	sum := sha256.Sum256(secret)
+
+	return SigningKey{
+		ID:         base64.RawURLEncoding.EncodeToString(sum[:])[:16],
+		Method:     jwt.SigningMethodHS512,
+		PrivateKey: secret,
+	}
+}
+
+// ParseSigningKey parses a PEM encoded private key (PKCS#8, PKCS#1 or SEC 1)
+// for the given asymmetric algorithm. The key ID is the RFC 7638 thumbprint
+// of the public key.
+func ParseSigningKey(algorithm string, pemData []byte) (SigningKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return SigningKey{}, errors.New("no PEM block found")
+	}
+
+	privateKey, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return newSigningKey(algorithm, privateKey)
+}
+
+// GenerateSigningKey creates a new random key for the given algorithm.
+func GenerateSigningKey(algorithm string) (SigningKey, error) {
+	var (
+		privateKey any
+		err        error
+	)
+
+	switch algorithm {
+	case AlgorithmHS512:
+		secret := make([]byte, 64)
+		if _, err := rand.Read(secret); err != nil {
+			return SigningKey{}, err
+		}
+
+		return NewSecretSigningKey(secret), nil
+	case AlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmES256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return SigningKey{}, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return newSigningKey(algorithm, privateKey)
+}
+
+// MarshalSigningKey encodes the private part of the key, the HMAC secret or
+// the PKCS#8 DER of an asymmetric key, to be read back by
+// UnmarshalSigningKey.
+func MarshalSigningKey(key SigningKey) ([]byte, error) {
+	if secret, ok := key.PrivateKey.([]byte); ok {
+		return secret, nil
+	}
+
+	return x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+}
+
+func UnmarshalSigningKey(algorithm string, data []byte) (SigningKey, error) {
+	if algorithm == AlgorithmHS512 {
+		return NewSecretSigningKey(data), nil
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return newSigningKey(algorithm, privateKey)
+}
+
+func newSigningKey(algorithm string, privateKey any) (SigningKey, error) {
+	key := SigningKey{
+		PrivateKey: privateKey,
+	}
+
+	switch algorithm {
+	case AlgorithmRS256:
+		rsaKey, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return SigningKey{}, ErrKeyAlgorithmMismatch
+		}
+
+		key.Method = jwt.SigningMethodRS256
+		key.PublicKey = &rsaKey.PublicKey
+	case AlgorithmES256:
+		ecKey, ok := privateKey.(*ecdsa.PrivateKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return SigningKey{}, ErrKeyAlgorithmMismatch
+		}
+
+		key.Method = jwt.SigningMethodES256
+		key.PublicKey = &ecKey.PublicKey
+	case AlgorithmEdDSA:
+		edKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return SigningKey{}, ErrKeyAlgorithmMismatch
+		}
+
+		key.Method = jwt.SigningMethodEdDSA
+		key.PublicKey = edKey.Public()
+	default:
+		return SigningKey{}, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	jwk, err := newJWK(key)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	key.ID = jwk.Thumbprint()
+
+	return key, nil
+}
+
+func parsePrivateKey(der []byte) (any, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("unsupported private key format")
+}
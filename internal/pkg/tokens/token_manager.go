@@ -4,27 +4,44 @@ import (
 	"time"
 
 	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
-	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/secrets"
 )
 
+type TokenManagerConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	Issuer          string
+	Audience        []string
+}
+
 type TokenManager struct {
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
-	secretManager   secrets.SecretManager
+	issuer          string
+	audience        []string
+	keyRing         *KeyRing
 }
 
-func NewTokenManager(accessTokenTTL time.Duration, refreshTokenTTL time.Duration, secretManager secrets.SecretManager) TokenManager {
+func NewTokenManager(cfg TokenManagerConfig, keyRing *KeyRing) TokenManager {
 	return TokenManager{
-		accessTokenTTL:  accessTokenTTL,
-		refreshTokenTTL: refreshTokenTTL,
-		secretManager:   secretManager,
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
+		issuer:          cfg.Issuer,
+		audience:        cfg.Audience,
+		keyRing:         keyRing,
 	}
 }
 
-func (t TokenManager) NewAccessToken() (models.AccessToken, error) {
-	return models.NewAccessToken(t.accessTokenTTL, t.secretManager.SecretKey())
+func (t TokenManager) NewAccessToken(user *models.User, session *models.Session) (models.AccessToken, error) {
+	key := t.keyRing.ActiveKey()
+	claims := models.NewAccessTokenClaims(user, session, t.issuer, t.audience, t.accessTokenTTL)
+
+	return models.NewAccessToken(claims, key.ID, key.Method, key.PrivateKey)
 }
 
 func (t TokenManager) NewRefreshToken() (models.RefreshToken, error) {
 	return models.NewRefreshToken(t.refreshTokenTTL)
 }
+
+func (t TokenManager) JWKS() JWKS {
+	return t.keyRing.JWKS()
+}
@@ -0,0 +1,32 @@
+package tokens
+
+import (
+	"context"
+
+	"github.com/rozhnof/stakewolle-auth-service/pkg/verifier"
+)
+
+// VerifierKeySet exposes the key ring to the token verifier, so this service
+// verifies its own tokens without fetching the JWKS.
+type VerifierKeySet struct {
+	ring *KeyRing
+}
+
+func NewVerifierKeySet(ring *KeyRing) VerifierKeySet {
+	return VerifierKeySet{
+		ring: ring,
+	}
+}
+
+func (s VerifierKeySet) Key(_ context.Context, keyID string) (verifier.Key, error) {
+	key, ok := s.ring.VerificationKey(keyID)
+	if !ok {
+		return verifier.Key{}, verifier.ErrUnknownKey
+	}
+
+	if key.PublicKey == nil {
+		return verifier.Key{Algorithm: key.Method.Alg(), Key: key.PrivateKey}, nil
+	}
+
+	return verifier.Key{Algorithm: key.Method.Alg(), Key: key.PublicKey}, nil
+}
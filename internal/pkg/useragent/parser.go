@@ -0,0 +1,69 @@
+package useragent
+
+import "strings"
+
+const unknown = "Unknown"
+
+type rule struct {
+	token string
+	name  string
+}
+
+// the order matters: more specific tokens go first, e.g. Edge and Opera user
+// agents also contain "Chrome", and Chrome ones contain "Safari"
+var browserRules = []rule{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"okhttp/", "Android app"},
+	{"CFNetwork/", "iOS app"},
+	{"curl/", "curl"},
+}
+
+var osRules = []rule{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// Parse returns a short human readable label such as "Chrome on macOS".
+func Parse(userAgent string) string {
+	if userAgent == "" {
+		return unknown
+	}
+
+	browser := match(browserRules, userAgent)
+	os := match(osRules, userAgent)
+
+	switch {
+	case browser == unknown && os == unknown:
+		return unknown
+	case os == unknown:
+		return browser
+	case browser == unknown:
+		return os
+	default:
+		return browser + " on " + os
+	}
+}
+
+func match(rules []rule, userAgent string) string {
+	for _, r := range rules {
+		if strings.Contains(userAgent, r.token) {
+			return r.name
+		}
+	}
+
+	return unknown
+}
@@ -2,11 +2,18 @@ package http_handlers
 
 import (
 	"log/slog"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/gin-gonic/gin"
 	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/useragent"
 	"go.opentelemetry.io/otel/trace"
 )
 
+const maxUserAgentLength = 512
+
 type AuthHandler struct {
 	log         *slog.Logger
 	userService *services.UserService
@@ -20,3 +27,29 @@ func NewAuthHandler(service *services.UserService, log *slog.Logger, tracer trac
 		tracer:      tracer,
 	}
 }
+
+// clientInfo describes the client of the request. The IP address honours the
+// forwarding headers only when the request comes from a trusted proxy.
+func clientInfo(c *gin.Context) models.ClientInfo {
+	userAgent := truncateUTF8(strings.ToValidUTF8(c.Request.UserAgent(), ""), maxUserAgentLength)
+
+	return models.ClientInfo{
+		UserAgent: userAgent,
+		IPAddress: c.ClientIP(),
+		Device:    useragent.Parse(userAgent),
+	}
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune, Postgres
+// rejects invalid UTF-8.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,75 @@
+package http_handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IntrospectRequest struct {
+	Token         string `form:"token"           binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"`
+}
+
+type IntrospectResponse struct {
+	Active    bool   `json:"active"`
+	TokenType string `json:"token_type,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	TokenID   string `json:"jti,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// Introspect @Summary Token introspection
+// @Description Reports whether an access or refresh token is active (RFC 7662)
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security BasicAuth
+// @Param token formData string true "Token to introspect"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200 {object} IntrospectResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.Introspect")
+	defer span.End()
+
+	var request IntrospectRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	introspection, err := h.oauthService.Introspect(ctx, request.Token, request.TokenTypeHint)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !introspection.Active {
+		c.JSON(http.StatusOK, IntrospectResponse{Active: false})
+		return
+	}
+
+	response := IntrospectResponse{
+		Active:    true,
+		TokenType: introspection.TokenType,
+		Subject:   introspection.UserID.String(),
+		SessionID: introspection.SessionID.String(),
+		TokenID:   introspection.TokenID,
+		Username:  introspection.Username,
+		Scope:     introspection.Scope,
+		ExpiresAt: introspection.ExpiresAt.Unix(),
+	}
+
+	if !introspection.IssuedAt.IsZero() {
+		response.IssuedAt = introspection.IssuedAt.Unix()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -38,7 +38,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	at, rt, err := h.userService.Login(ctx, request.Username, request.Password)
+	at, rt, err := h.userService.Login(ctx, request.Username, request.Password, clientInfo(c))
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidPassword) {
 			c.String(http.StatusOK, "invalid username or password")
@@ -0,0 +1,63 @@
+package http_handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/ginauth"
+)
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout @Summary User logout
+// @Description Revokes the session of the refresh token, or of the access token when no refresh token is given
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param logout body LogoutRequest false "Logout Request"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Not Found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.Logout")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// the body is optional
+	var request LogoutRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	accessToken := models.AccessToken{
+		ID:        principal.TokenID,
+		ExpiredAt: principal.ExpiresAt,
+	}
+
+	if err := h.userService.Logout(ctx, principal.UserID, principal.SessionID, accessToken, request.RefreshToken); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.String(http.StatusNotFound, "session not found")
+			return
+		}
+
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
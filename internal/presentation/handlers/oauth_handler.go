@@ -0,0 +1,43 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OAuthHandler struct {
+	log          *slog.Logger
+	oauthService *services.OAuthService
+	tracer       trace.Tracer
+}
+
+func NewOAuthHandler(service *services.OAuthService, log *slog.Logger, tracer trace.Tracer) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: service,
+		log:          log,
+		tracer:       tracer,
+	}
+}
+
+// AuthenticateClient accepts client credentials either as HTTP Basic auth or
+// as client_id and client_secret form parameters (RFC 6749, section 2.3.1).
+func (h *OAuthHandler) AuthenticateClient(c *gin.Context) {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+
+	if err := h.oauthService.AuthenticateClient(c.Request.Context(), clientID, clientSecret); err != nil {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
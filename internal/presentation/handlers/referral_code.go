@@ -0,0 +1,268 @@
+package http_handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
+	"github.com/rozhnof/stakewolle-auth-service/internal/domain/models"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/ginauth"
+)
+
+type CreateReferralCodeRequest struct {
+	// Code is a vanity code, a short one is generated when it is omitted.
+	Code           string    `json:"code"`
+	Campaign       string    `json:"campaign"`
+	MaxRedemptions int       `json:"max_redemptions"`
+	ExpiredAt      time.Time `json:"expired_at"`
+}
+
+type ReferralCodeResponse struct {
+	Code           string    `json:"code"`
+	Campaign       string    `json:"campaign,omitempty"`
+	MaxRedemptions int       `json:"max_redemptions,omitempty"`
+	Redemptions    int       `json:"redemptions"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiredAt      time.Time `json:"expired_at"`
+}
+
+func newReferralCodeResponse(code *models.ReferralCode) ReferralCodeResponse {
+	return ReferralCodeResponse{
+		Code:           code.Code,
+		Campaign:       code.Campaign,
+		MaxRedemptions: code.MaxRedemptions,
+		Redemptions:    code.Redemptions,
+		CreatedAt:      code.CreatedAt,
+		ExpiredAt:      code.ExpiredAt,
+	}
+}
+
+// ReferralCodeLookupResponse is what anyone may learn about another user's
+// code, its campaign and usage stay private to the owner.
+type ReferralCodeLookupResponse struct {
+	Code      string    `json:"code"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+func newReferralCodeLookupResponse(code *models.ReferralCode) ReferralCodeLookupResponse {
+	return ReferralCodeLookupResponse{
+		Code:      code.Code,
+		ExpiredAt: code.ExpiredAt,
+	}
+}
+
+// CreateReferralCode @Summary Create referral code
+// @Description Creates a referral code for the authenticated user, who may have one active code at a time. A vanity code may be chosen, otherwise a short one is generated
+// @Tags referral
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateReferralCodeRequest false "Create Referral Code Request"
+// @Success 201 {object} ReferralCodeResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Conflict"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /referral-code [post]
+func (h *ReferralHandler) CreateReferralCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.CreateReferralCode")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// the body is optional
+	var request CreateReferralCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	code, err := h.referralService.CreateCode(ctx, principal.UserID, services.ReferralCodeOptions{
+		Code:           request.Code,
+		Campaign:       request.Campaign,
+		MaxRedemptions: request.MaxRedemptions,
+		ExpiredAt:      request.ExpiredAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidReferralCodeExpiry):
+			c.String(http.StatusBadRequest, "invalid expiry")
+		case errors.Is(err, services.ErrInvalidVanityCode):
+			c.String(http.StatusBadRequest, "code must be 4 to 32 letters, digits or inner hyphens")
+		case errors.Is(err, services.ErrProfaneReferralCode):
+			c.String(http.StatusBadRequest, "code is not allowed")
+		case errors.Is(err, services.ErrInvalidCampaign):
+			c.String(http.StatusBadRequest, "invalid campaign")
+		case errors.Is(err, services.ErrInvalidMaxRedemptions):
+			c.String(http.StatusBadRequest, "invalid max redemptions")
+		case errors.Is(err, services.ErrReferralCodeExists):
+			c.String(http.StatusConflict, "referral code already exists")
+		case errors.Is(err, services.ErrReferralCodeTaken):
+			c.String(http.StatusConflict, "code is taken")
+		default:
+			c.String(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, newReferralCodeResponse(code))
+}
+
+// GetReferralCode @Summary Get referral code
+// @Description Returns the active referral code of the authenticated user
+// @Tags referral
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ReferralCodeResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Not Found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /referral-code [get]
+func (h *ReferralHandler) GetReferralCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.GetReferralCode")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	code, err := h.referralService.GetCode(ctx, principal.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrReferralCodeNotFound) {
+			c.String(http.StatusNotFound, "referral code not found")
+			return
+		}
+
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, newReferralCodeResponse(code))
+}
+
+// DeleteReferralCode @Summary Delete referral code
+// @Description Deletes the active referral code of the authenticated user
+// @Tags referral
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Not Found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /referral-code [delete]
+func (h *ReferralHandler) DeleteReferralCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.DeleteReferralCode")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.referralService.DeleteCode(ctx, principal.UserID); err != nil {
+		if errors.Is(err, services.ErrReferralCodeNotFound) {
+			c.String(http.StatusNotFound, "referral code not found")
+			return
+		}
+
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+const (
+	queryParamUsername = "username"
+	queryParamEmail    = "email"
+)
+
+// LookupReferralCode @Summary Look up referral code
+// @Description Returns the active referral code of a user found by username or verified email, unless the user opted out of discoverability
+// @Tags referral
+// @Produce json
+// @Param username query string false "Username of the referrer"
+// @Param email query string false "Verified email of the referrer"
+// @Success 200 {object} ReferralCodeLookupResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "Not Found"
+// @Failure 429 {string} string "Too Many Requests"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /referral-code/lookup [get]
+func (h *ReferralHandler) LookupReferralCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.LookupReferralCode")
+	defer span.End()
+
+	username := c.Query(queryParamUsername)
+	email := c.Query(queryParamEmail)
+
+	if (username == "") == (email == "") {
+		c.String(http.StatusBadRequest, "either username or email is required")
+		return
+	}
+
+	code, err := h.referralService.LookupCode(ctx, c.ClientIP(), username, email)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRateLimited):
+			c.String(http.StatusTooManyRequests, "too many requests")
+		case errors.Is(err, services.ErrReferralCodeNotFound):
+			c.String(http.StatusNotFound, "referral code not found")
+		default:
+			c.String(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newReferralCodeLookupResponse(code))
+}
+
+type SetReferralDiscoverableRequest struct {
+	Discoverable *bool `json:"discoverable" binding:"required"`
+}
+
+// SetReferralDiscoverable @Summary Set referral discoverability
+// @Description Sets whether others can look up the referral code of the authenticated user
+// @Tags referral
+// @Accept json
+// @Security BearerAuth
+// @Param request body SetReferralDiscoverableRequest true "Set Referral Discoverable Request"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /referral-code/discoverable [put]
+func (h *ReferralHandler) SetReferralDiscoverable(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.SetReferralDiscoverable")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var request SetReferralDiscoverableRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.referralService.SetDiscoverable(ctx, principal.UserID, *request.Discoverable); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
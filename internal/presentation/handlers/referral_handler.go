@@ -0,0 +1,22 @@
+package http_handlers
+
+import (
+	"log/slog"
+
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReferralHandler struct {
+	log             *slog.Logger
+	referralService *services.ReferralService
+	tracer          trace.Tracer
+}
+
+func NewReferralHandler(service *services.ReferralService, log *slog.Logger, tracer trace.Tracer) *ReferralHandler {
+	return &ReferralHandler{
+		referralService: service,
+		log:             log,
+		tracer:          tracer,
+	}
+}
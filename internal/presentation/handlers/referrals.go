@@ -0,0 +1,299 @@
+package http_handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	pathParamUserID  = "id"
+	queryParamLimit  = "limit"
+	queryParamOffset = "offset"
+	queryParamDepth  = "depth"
+	queryParamFrom   = "from"
+	queryParamTo     = "to"
+
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+type RefereeResponse struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+type ListReferralsResponse struct {
+	Referrals []RefereeResponse `json:"referrals"`
+	Limit     int               `json:"limit"`
+	Offset    int               `json:"offset"`
+}
+
+type ReferralStatsResponse struct {
+	Direct int64   `json:"direct"`
+	Total  int64   `json:"total"`
+	Levels []int64 `json:"levels"`
+}
+
+type ReferralTreeNode struct {
+	UserID       uuid.UUID           `json:"user_id"`
+	Username     string              `json:"username,omitempty"`
+	RegisteredAt *time.Time          `json:"registered_at,omitempty"`
+	Referrals    []*ReferralTreeNode `json:"referrals"`
+}
+
+type ReferralTreeResponse struct {
+	ReferralTreeNode
+	Truncated bool `json:"truncated"`
+}
+
+// ListReferrals @Summary List referrals
+// @Description Lists the users a referrer brought in directly, most recent first. Allowed for the referrer and admins
+// @Tags referral
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Referrer ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Page offset" default(0)
+// @Success 200 {object} ListReferralsResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /users/{id}/referrals [get]
+func (h *ReferralHandler) ListReferrals(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.ListReferrals")
+	defer span.End()
+
+	referrerID, err := uuid.Parse(c.Param(pathParamUserID))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	limit, err := intQuery(c, queryParamLimit, defaultPageSize)
+	if err != nil || limit <= 0 || limit > maxPageSize {
+		c.String(http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	offset, err := intQuery(c, queryParamOffset, 0)
+	if err != nil || offset < 0 {
+		c.String(http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	referees, err := h.referralService.ListReferees(ctx, referrerID, limit, offset)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ListReferralsResponse{
+		Referrals: make([]RefereeResponse, 0, len(referees)),
+		Limit:     limit,
+		Offset:    offset,
+	}
+
+	for _, referee := range referees {
+		response.Referrals = append(response.Referrals, RefereeResponse{
+			UserID:       referee.UserID,
+			Username:     referee.Username,
+			RegisteredAt: referee.RegisteredAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReferralStats @Summary Referral statistics
+// @Description Counts the referees of a referrer per level of the referral tree. Allowed for the referrer and admins
+// @Tags referral
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Referrer ID"
+// @Success 200 {object} ReferralStatsResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /users/{id}/referrals/stats [get]
+func (h *ReferralHandler) ReferralStats(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.ReferralStats")
+	defer span.End()
+
+	referrerID, err := uuid.Parse(c.Param(pathParamUserID))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	stats, err := h.referralService.Stats(ctx, referrerID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ReferralStatsResponse{
+		Direct: stats.Direct(),
+		Total:  stats.Total(),
+		Levels: stats.Levels,
+	}
+
+	if response.Levels == nil {
+		response.Levels = []int64{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReferralTree @Summary Referral tree
+// @Description Returns the referral tree below a referrer. Allowed for the referrer and admins
+// @Tags referral
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Referrer ID"
+// @Param depth query int false "Levels to return, capped by the server"
+// @Success 200 {object} ReferralTreeResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /users/{id}/referrals/tree [get]
+func (h *ReferralHandler) ReferralTree(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.ReferralTree")
+	defer span.End()
+
+	referrerID, err := uuid.Parse(c.Param(pathParamUserID))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	depth, err := intQuery(c, queryParamDepth, 0)
+	if err != nil || depth < 0 {
+		c.String(http.StatusBadRequest, "invalid depth")
+		return
+	}
+
+	referees, truncated, err := h.referralService.Tree(ctx, referrerID, depth)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ReferralTreeResponse{
+		ReferralTreeNode: ReferralTreeNode{
+			UserID:    referrerID,
+			Referrals: []*ReferralTreeNode{},
+		},
+		Truncated: truncated,
+	}
+
+	// referees come level by level, so a referrer is always placed before
+	// its referees
+	nodes := map[uuid.UUID]*ReferralTreeNode{referrerID: &response.ReferralTreeNode}
+
+	for _, referee := range referees {
+		node := &ReferralTreeNode{
+			UserID:       referee.UserID,
+			Username:     referee.Username,
+			RegisteredAt: &referee.RegisteredAt,
+			Referrals:    []*ReferralTreeNode{},
+		}
+
+		nodes[referee.UserID] = node
+
+		if parent, ok := nodes[referee.ReferrerID]; ok {
+			parent.Referrals = append(parent.Referrals, node)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type CampaignRedemptionsResponse struct {
+	Campaign    string `json:"campaign"`
+	Redemptions int64  `json:"redemptions"`
+	Referrers   int64  `json:"referrers"`
+}
+
+type ListCampaignsResponse struct {
+	Campaigns []CampaignRedemptionsResponse `json:"campaigns"`
+	From      time.Time                     `json:"from"`
+	To        time.Time                     `json:"to"`
+}
+
+// ListCampaigns @Summary Referral campaigns
+// @Description Counts the registrations with referral codes per campaign, codes without a campaign are counted under an empty one. Allowed for admins
+// @Tags referral
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start of the period in RFC 3339, all time when omitted"
+// @Param to query string false "End of the period in RFC 3339, now when omitted"
+// @Success 200 {object} ListCampaignsResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /referral-campaigns [get]
+func (h *ReferralHandler) ListCampaigns(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.ListCampaigns")
+	defer span.End()
+
+	from, err := timeQuery(c, queryParamFrom, time.Time{})
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid from")
+		return
+	}
+
+	to, err := timeQuery(c, queryParamTo, time.Now())
+	if err != nil || !to.After(from) {
+		c.String(http.StatusBadRequest, "invalid to")
+		return
+	}
+
+	campaigns, err := h.referralService.RedemptionsByCampaign(ctx, from.UTC(), to.UTC())
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ListCampaignsResponse{
+		Campaigns: make([]CampaignRedemptionsResponse, 0, len(campaigns)),
+		From:      from,
+		To:        to,
+	}
+
+	for _, campaign := range campaigns {
+		response.Campaigns = append(response.Campaigns, CampaignRedemptionsResponse{
+			Campaign:    campaign.Campaign,
+			Redemptions: campaign.Redemptions,
+			Referrers:   campaign.Referrers,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func intQuery(c *gin.Context, key string, defaultValue int) (int, error) {
+	value, ok := c.GetQuery(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+func timeQuery(c *gin.Context, key string, defaultValue time.Time) (time.Time, error) {
+	value, ok := c.GetQuery(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -39,8 +39,13 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	at, rt, err := h.userService.Refresh(ctx, request.RefreshToken)
+	at, rt, err := h.userService.Refresh(ctx, request.RefreshToken, clientInfo(c))
 	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			c.String(http.StatusUnauthorized, "Refresh token reuse detected, session revoked")
+			return
+		}
+
 		if errors.Is(err, services.ErrUnauthorizedRefresh) {
 			c.String(http.StatusUnauthorized, "Unauthorized refresh token")
 			return
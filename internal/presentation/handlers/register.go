@@ -5,6 +5,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
 )
 
 type RegisterRequest struct {
@@ -27,6 +29,7 @@ const (
 // @Accept json
 // @Produce json
 // @Param register body RegisterRequest true "Register Request"
+// @Param ref query string false "Referral code"
 // @Success 200 {object} RegisterResponse
 // @Failure 400 {string} string "Bad Request"
 // @Failure 500 {string} string "Internal Server Error"
@@ -45,6 +48,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	registeredUser, err := h.userService.Register(ctx, request.Username, request.Password, referralCode)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidReferralCode) {
+			c.String(http.StatusBadRequest, "invalid referral code")
+			return
+		}
+
+		if errors.Is(err, services.ErrReferralCodeExhausted) {
+			c.String(http.StatusBadRequest, "referral code exhausted")
+			return
+		}
+
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,44 @@
+package http_handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevokeRequest struct {
+	Token         string `form:"token"           binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"`
+}
+
+// Revoke @Summary Token revocation
+// @Description Revokes an access or refresh token (RFC 7009)
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Security BasicAuth
+// @Param token formData string true "Token to revoke"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 503 {string} string "Service Unavailable"
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.Revoke")
+	defer span.End()
+
+	var request RevokeRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	if err := h.oauthService.Revoke(ctx, request.Token, request.TokenTypeHint); err != nil {
+		h.log.Error("revoke token", slog.String("error", err.Error()))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "temporarily_unavailable"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
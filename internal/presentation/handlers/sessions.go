@@ -0,0 +1,140 @@
+package http_handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/stakewolle-auth-service/internal/application/services"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/ginauth"
+)
+
+const pathParamSessionID = "id"
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Device     string    `json:"device"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+// ListSessions @Summary List sessions
+// @Description Lists the active sessions of the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ListSessionsResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.ListSessions")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessions, err := h.userService.ListSessions(ctx, principal.UserID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ListSessionsResponse{
+		Sessions: make([]SessionResponse, 0, len(sessions)),
+	}
+
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, SessionResponse{
+			ID:         session.ID,
+			Current:    session.ID == principal.SessionID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiredAt:  session.RefreshToken.ExpiredAt,
+			UserAgent:  session.ClientInfo.UserAgent,
+			IPAddress:  session.ClientInfo.IPAddress,
+			Device:     session.ClientInfo.Device,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession @Summary Revoke session
+// @Description Revokes one of the sessions of the authenticated user
+// @Tags auth
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Not Found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.RevokeSession")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param(pathParamSessionID))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid session id")
+		return
+	}
+
+	if err := h.userService.RevokeSession(ctx, principal.UserID, sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.String(http.StatusNotFound, "session not found")
+			return
+		}
+
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll @Summary Logout everywhere
+// @Description Revokes every session of the authenticated user
+// @Tags auth
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.LogoutAll")
+	defer span.End()
+
+	principal, ok := ginauth.Principal(c)
+	if !ok {
+		c.String(http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.userService.LogoutAll(ctx, principal.UserID); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,43 @@
+package http_handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rozhnof/stakewolle-auth-service/internal/pkg/tokens"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type KeySetProvider interface {
+	JWKS() tokens.JWKS
+}
+
+type WellKnownHandler struct {
+	log    *slog.Logger
+	keys   KeySetProvider
+	tracer trace.Tracer
+}
+
+func NewWellKnownHandler(keys KeySetProvider, log *slog.Logger, tracer trace.Tracer) *WellKnownHandler {
+	return &WellKnownHandler{
+		keys:   keys,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+// JWKS @Summary JSON Web Key Set
+// @Description Returns the public keys used to verify access tokens
+// @Tags well-known
+// @Produce json
+// @Success 200 {object} tokens.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	_, span := h.tracer.Start(c.Request.Context(), "WellKnownHandler.JWKS")
+	defer span.End()
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(tokens.JWKSMaxAge.Seconds())))
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -1,2 +1,5 @@
 DROP INDEX idx_username;
 DROP INDEX idx_refresh_token;
+DROP TABLE session;
+DROP TABLE referral_code;
+DROP TABLE users;
@@ -1,6 +1,6 @@
 CREATE TABLE users (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    referrer_id REFERENCES users(id)
+    referrer_id UUID REFERENCES users(id),
     username VARCHAR(50) NOT NULL UNIQUE,
     hash_password TEXT NOT NULL,
     deleted_at TIMESTAMP
@@ -0,0 +1,2 @@
+DROP INDEX idx_refresh_token_history_session_id;
+DROP TABLE refresh_token_history;
@@ -0,0 +1,7 @@
+CREATE TABLE refresh_token_history (
+    token_hash CHAR(64) PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES session(id) ON DELETE CASCADE,
+    rotated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refresh_token_history_session_id ON refresh_token_history (session_id);
@@ -0,0 +1,10 @@
+-- plaintext tokens cannot be recovered, every existing session is revoked
+DROP INDEX idx_session_refresh_token_hash;
+
+ALTER TABLE session ADD COLUMN refresh_token VARCHAR(255);
+UPDATE session SET is_revoked = TRUE;
+
+ALTER TABLE session DROP COLUMN refresh_token_prefix;
+ALTER TABLE session DROP COLUMN refresh_token_hash;
+
+CREATE INDEX idx_refresh_token ON session (refresh_token);
@@ -0,0 +1,14 @@
+ALTER TABLE session ADD COLUMN refresh_token_hash CHAR(64);
+ALTER TABLE session ADD COLUMN refresh_token_prefix VARCHAR(8);
+
+UPDATE session
+SET
+    refresh_token_hash = encode(sha256(convert_to(refresh_token, 'UTF8')), 'hex'),
+    refresh_token_prefix = left(refresh_token, 8)
+WHERE
+    refresh_token IS NOT NULL;
+
+DROP INDEX idx_refresh_token;
+ALTER TABLE session DROP COLUMN refresh_token;
+
+CREATE UNIQUE INDEX idx_session_refresh_token_hash ON session (refresh_token_hash);
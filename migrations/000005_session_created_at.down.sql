@@ -0,0 +1,3 @@
+DROP INDEX idx_session_user_id_created_at;
+
+ALTER TABLE session DROP COLUMN created_at;
@@ -0,0 +1,3 @@
+ALTER TABLE session ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_session_user_id_created_at ON session (user_id, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE session ADD COLUMN last_used_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE session SET last_used_at = created_at;
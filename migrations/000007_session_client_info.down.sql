@@ -0,0 +1,3 @@
+ALTER TABLE session DROP COLUMN device;
+ALTER TABLE session DROP COLUMN ip_address;
+ALTER TABLE session DROP COLUMN user_agent;
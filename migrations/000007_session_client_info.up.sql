@@ -0,0 +1,3 @@
+ALTER TABLE session ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE session ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE session ADD COLUMN device VARCHAR(100) NOT NULL DEFAULT '';
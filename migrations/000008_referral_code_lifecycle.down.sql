@@ -0,0 +1,6 @@
+DROP INDEX idx_users_referrer_id;
+DROP INDEX idx_referral_code_user_id;
+
+ALTER TABLE referral_code DROP COLUMN created_at;
+ALTER TABLE referral_code ALTER COLUMN expired_at DROP NOT NULL;
+ALTER TABLE referral_code ALTER COLUMN user_id DROP NOT NULL;
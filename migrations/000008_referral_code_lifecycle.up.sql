@@ -0,0 +1,11 @@
+DELETE FROM referral_code WHERE user_id IS NULL OR expired_at IS NULL;
+
+ALTER TABLE referral_code ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE referral_code ALTER COLUMN expired_at SET NOT NULL;
+ALTER TABLE referral_code ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- one code per user that is not deleted; an expired code is deleted when
+-- the user creates a new one
+CREATE UNIQUE INDEX idx_referral_code_user_id ON referral_code (user_id) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_users_referrer_id ON users (referrer_id);
@@ -0,0 +1,5 @@
+DROP INDEX idx_users_email;
+
+ALTER TABLE users DROP COLUMN referral_discoverable;
+ALTER TABLE users DROP COLUMN email_verified;
+ALTER TABLE users DROP COLUMN email;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email VARCHAR(254);
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN referral_discoverable BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE UNIQUE INDEX idx_users_email ON users (lower(email)) WHERE deleted_at IS NULL;
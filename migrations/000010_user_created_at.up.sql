@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW();
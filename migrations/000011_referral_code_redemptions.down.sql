@@ -0,0 +1,8 @@
+DROP TABLE referral_redemption;
+
+DROP INDEX idx_referral_code_code;
+
+ALTER TABLE referral_code DROP COLUMN redemptions;
+ALTER TABLE referral_code DROP COLUMN max_redemptions;
+ALTER TABLE referral_code DROP COLUMN campaign;
+ALTER TABLE referral_code DROP COLUMN code;
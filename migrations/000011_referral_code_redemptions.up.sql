@@ -0,0 +1,24 @@
+-- existing codes keep working: their code is the upper-cased UUID that was
+-- shared before
+ALTER TABLE referral_code ADD COLUMN code VARCHAR(36);
+UPDATE referral_code SET code = upper(id::text);
+ALTER TABLE referral_code ALTER COLUMN code SET NOT NULL;
+
+ALTER TABLE referral_code ADD COLUMN campaign VARCHAR(64);
+ALTER TABLE referral_code ADD COLUMN max_redemptions INTEGER;
+ALTER TABLE referral_code ADD COLUMN redemptions INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX idx_referral_code_code ON referral_code (code) WHERE deleted_at IS NULL;
+
+CREATE TABLE referral_redemption (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    referral_code_id UUID NOT NULL REFERENCES referral_code(id),
+    referrer_id UUID NOT NULL REFERENCES users(id),
+    referee_id UUID NOT NULL UNIQUE REFERENCES users(id),
+    code VARCHAR(36) NOT NULL,
+    campaign VARCHAR(64),
+    redeemed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_referral_redemption_campaign ON referral_redemption (campaign, redeemed_at);
+CREATE INDEX idx_referral_redemption_referral_code_id ON referral_redemption (referral_code_id);
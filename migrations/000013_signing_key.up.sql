@@ -0,0 +1,9 @@
+-- private signing keys: access to this table grants signing access tokens
+CREATE TABLE signing_key (
+    id VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key BYTEA NOT NULL,
+    activates_at TIMESTAMP NOT NULL,
+    retired_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,98 @@
+// Package ginauth provides gin middleware that authenticates requests with
+// access tokens checked by the verifier package.
+package ginauth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rozhnof/stakewolle-auth-service/pkg/verifier"
+)
+
+const principalContextKey = "auth.principal"
+
+// Authenticate requires a valid bearer access token. The principal is stored
+// both in the gin context and in the request context, so it can be read with
+// Principal or verifier.PrincipalFromContext further down the stack.
+func Authenticate(v *verifier.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Header("WWW-Authenticate", `Bearer`)
+			c.String(http.StatusUnauthorized, "missing access token")
+			c.Abort()
+			return
+		}
+
+		principal, err := v.Verify(c.Request.Context(), token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.String(http.StatusUnauthorized, "invalid access token")
+			c.Abort()
+			return
+		}
+
+		c.Set(principalContextKey, principal)
+		c.Request = c.Request.WithContext(verifier.ContextWithPrincipal(c.Request.Context(), principal))
+
+		c.Next()
+	}
+}
+
+// RequireRole rejects authenticated requests whose principal lacks the role.
+// It must run after Authenticate.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := Principal(c)
+		if !ok || !principal.HasRole(role) {
+			c.String(http.StatusForbidden, "forbidden")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrRole rejects authenticated requests unless the path parameter
+// is the principal's user ID or the principal has the role. The parameter is
+// compared as a UUID, so its case and format do not matter. It must run after
+// Authenticate.
+func RequireSelfOrRole(param string, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := Principal(c)
+		if !ok || (!isUserID(c.Param(param), principal.UserID) && !principal.HasRole(role)) {
+			c.String(http.StatusForbidden, "forbidden")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func Principal(c *gin.Context) (*verifier.Principal, bool) {
+	value, ok := c.Get(principalContextKey)
+	if !ok {
+		return nil, false
+	}
+
+	principal, ok := value.(*verifier.Principal)
+	return principal, ok
+}
+
+func isUserID(value string, userID uuid.UUID) bool {
+	id, err := uuid.Parse(value)
+	return err == nil && id == userID
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
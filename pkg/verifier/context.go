@@ -0,0 +1,16 @@
+package verifier
+
+import "context"
+
+type principalKeyType struct{}
+
+var principalKey = principalKeyType{}
+
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,200 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval limits how often an unknown key ID can trigger a fetch.
+const minRefreshInterval = time.Minute
+
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// RemoteKeySet is a KeySet backed by the auth service JWKS endpoint. The set
+// is refetched once it is older than ttl, or when a token references a key
+// that is not known yet.
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+	ttl    time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]Key
+	fetchedAt time.Time
+}
+
+func NewRemoteKeySet(url string, client *http.Client, ttl time.Duration) *RemoteKeySet {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &RemoteKeySet{
+		url:    url,
+		client: client,
+		ttl:    ttl,
+		keys:   make(map[string]Key),
+	}
+}
+
+func (s *RemoteKeySet) Key(ctx context.Context, keyID string) (Key, error) {
+	s.mu.RLock()
+	key, ok := s.keys[keyID]
+	age := time.Since(s.fetchedAt)
+	s.mu.RUnlock()
+
+	if ok && age < s.ttl {
+		return key, nil
+	}
+
+	if ok || age >= minRefreshInterval {
+		if err := s.refresh(ctx); err != nil && !ok {
+			return Key{}, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if key, ok := s.keys[keyID]; ok {
+		return key, nil
+	}
+
+	return Key{}, ErrUnknownKey
+}
+
+func (s *RemoteKeySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]Key, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[k.KeyID] = Key{
+			Algorithm: k.Algorithm,
+			Key:       publicKey,
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
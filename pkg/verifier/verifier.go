@@ -0,0 +1,255 @@
+// Package verifier validates access tokens issued by the auth service. It is
+// meant to be shared by every Go service that accepts those tokens.
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+	ErrTokenRevoked     = errors.New("token is revoked")
+)
+
+// IsInvalidToken reports whether Verify rejected the token itself. Any other
+// error means the token could not be checked, e.g. the denylist is down.
+func IsInvalidToken(err error) bool {
+	for _, target := range []error{
+		ErrMalformedToken,
+		ErrUnknownKey,
+		ErrInvalidSignature,
+		ErrTokenExpired,
+		ErrTokenNotYetValid,
+		ErrInvalidIssuer,
+		ErrInvalidAudience,
+		ErrTokenRevoked,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var defaultAlgorithms = []string{"RS256", "ES256", "EdDSA"}
+
+// Key is a key that can verify token signatures: a public key for asymmetric
+// algorithms or the shared secret for HMAC.
+type Key struct {
+	Algorithm string
+	Key       any
+}
+
+type KeySet interface {
+	Key(ctx context.Context, keyID string) (Key, error)
+}
+
+// Denylist reports whether an access token was revoked before its expiry.
+type Denylist interface {
+	Contains(ctx context.Context, tokenID string) (bool, error)
+}
+
+type Config struct {
+	// Issuer is the expected iss claim, not checked when empty.
+	Issuer string
+	// Audiences lists the accepted aud values, the token must carry at least
+	// one of them. Not checked when empty.
+	Audiences []string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+	// Algorithms lists the accepted signing algorithms. Defaults to RS256,
+	// ES256 and EdDSA.
+	Algorithms []string
+	// Denylist is consulted with the jti claim of every token that is
+	// otherwise valid. Optional.
+	Denylist Denylist
+	// SessionDenylist is consulted with the sid claim, it revokes every
+	// token issued for a session at once. Optional.
+	SessionDenylist Denylist
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+	SessionID string   `json:"sid"`
+	Username  string   `json:"username"`
+	Roles     []string `json:"roles,omitempty"`
+}
+
+// Principal is the authenticated subject of a verified access token.
+type Principal struct {
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	TokenID   string
+	Username  string
+	Roles     []string
+	ExpiresAt time.Time
+	Claims    Claims
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Verifier struct {
+	keys   KeySet
+	cfg    Config
+	parser *jwt.Parser
+}
+
+func New(keys KeySet, cfg Config) *Verifier {
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = defaultAlgorithms
+	}
+
+	return &Verifier{
+		keys: keys,
+		cfg:  cfg,
+		parser: &jwt.Parser{
+			ValidMethods:         cfg.Algorithms,
+			SkipClaimsValidation: true,
+		},
+	}
+}
+
+// Verify parses the token, checks its signature and registered claims and
+// returns the principal it was issued for.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	var claims Claims
+
+	keyFunc := func(t *jwt.Token) (any, error) {
+		keyID, _ := t.Header["kid"].(string)
+
+		key, err := v.keys.Key(ctx, keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		// the key decides the algorithm, never the token header
+		if key.Algorithm != t.Method.Alg() {
+			return nil, ErrInvalidSignature
+		}
+
+		return key.Key, nil
+	}
+
+	if _, err := v.parser.ParseWithClaims(token, &claims, keyFunc); err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := v.validate(&claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if v.cfg.Denylist != nil {
+		revoked, err := v.cfg.Denylist.Contains(ctx, claims.ID)
+		if err != nil {
+			// fail closed, a token that cannot be checked is not trusted
+			return nil, fmt.Errorf("check denylist: %w", err)
+		}
+
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	if v.cfg.SessionDenylist != nil {
+		revoked, err := v.cfg.SessionDenylist.Contains(ctx, claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("check session denylist: %w", err)
+		}
+
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return newPrincipal(claims)
+}
+
+func (v *Verifier) validate(claims *Claims, now time.Time) error {
+	if claims.ExpiresAt == nil || now.Add(-v.cfg.Leeway).After(claims.ExpiresAt.Time) {
+		return ErrTokenExpired
+	}
+
+	if claims.NotBefore != nil && now.Add(v.cfg.Leeway).Before(claims.NotBefore.Time) {
+		return ErrTokenNotYetValid
+	}
+
+	if claims.IssuedAt != nil && now.Add(v.cfg.Leeway).Before(claims.IssuedAt.Time) {
+		return ErrTokenNotYetValid
+	}
+
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if len(v.cfg.Audiences) > 0 && !hasAudience(claims.Audience, v.cfg.Audiences) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+func hasAudience(tokenAudience jwt.ClaimStrings, accepted []string) bool {
+	for _, aud := range tokenAudience {
+		for _, a := range accepted {
+			if aud == a {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func parseError(err error) error {
+	switch {
+	case errors.Is(err, ErrUnknownKey), errors.Is(err, ErrInvalidSignature):
+		return err
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return fmt.Errorf("%w: %w", ErrMalformedToken, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+}
+
+func newPrincipal(claims Claims) (*Principal, error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid sub claim", ErrMalformedToken)
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid sid claim", ErrMalformedToken)
+	}
+
+	return &Principal{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenID:   claims.ID,
+		Username:  claims.Username,
+		Roles:     claims.Roles,
+		ExpiresAt: claims.ExpiresAt.Time,
+		Claims:    claims,
+	}, nil
+}